@@ -5,13 +5,17 @@ import (
 	"app/cache"
 	"app/config"
 	"app/db"
+	"app/filter"
+	"app/live"
 	"app/log"
+	"app/markdown"
 	"app/model"
 	"app/smtp"
 	"app/utils"
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"gorm.io/gorm"
@@ -37,8 +41,8 @@ func CreateJWTToken(user *model.User) (string, error) {
 	return tokenString, nil
 }
 
-// ParseJWTToken разбирает JWT токен и возвращает данные пользователя
-func ParseJWTToken(tokenString string) (*model.Token, error) {
+// parseClaims проверяет подпись JWT токена и возвращает его данные
+func parseClaims(tokenString string) (*model.Token, error) {
 	// Создаем экземпляр структуры Token для хранения данных из токена
 	tk := &model.Token{}
 
@@ -63,6 +67,41 @@ func ParseJWTToken(tokenString string) (*model.Token, error) {
 	return nil, errors.New("invalid token")
 }
 
+// ParseJWTToken разбирает JWT токен и возвращает данные пользователя
+func ParseJWTToken(tokenString string) (*model.Token, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Заблокированный модератором пользователь не может пользоваться уже выданным токеном
+	var user model.User
+	if err := db.App.Select("id, role, suspended_until").Where("id = ?", claims.UserId).Limit(1).Find(&user).Error; err != nil {
+		return nil, err
+	}
+	// Токен удаленного пользователя недействителен
+	if user.ID == 0 {
+		return nil, fmt.Errorf("Пользователь не найден")
+	}
+	if err := checkSuspended(&user); err != nil {
+		return nil, err
+	}
+
+	// Роль берется из БД: назначенный или снятый модератор получает или теряет права без повторного входа
+	claims.Role = user.Role
+	return claims, nil
+}
+
+// TokenUserID возвращает ID пользователя из JWT токена, проверяя только подпись, без обращения к базе.
+// Подходит там, где пользователя нужно лишь различать, например для ограничения частоты запросов.
+func TokenUserID(tokenString string) (uint, bool) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return 0, false
+	}
+	return claims.UserId, true
+}
+
 // CheckEmailAvailability проверяет, занята ли почта.
 // Если true, то почта занята.
 func CheckEmailAvailability(email string) error {
@@ -103,8 +142,14 @@ func Validate(user *model.User) error {
 	return nil
 }
 
-// RegisterUser регистрирует пользователя. Проверяет корректность почты и пароля, отправляет на почту письмо с кодом подтверждения.
-func RegisterUser(email, password, name string) (*model.Response, string, error) {
+// RegisterUser регистрирует пользователя. Проверяет решение задачи proof-of-work, корректность почты и пароля,
+// отправляет на почту письмо с кодом подтверждения.
+func RegisterUser(email, password, name string, proof model.ProofOfWork) (*model.Response, string, error) {
+	// Задача проверяется первой, чтобы без ее решения нельзя было даже узнать, занята ли почта
+	if err := checkProofOfWork(model.PowRegister, email, proof); err != nil {
+		return nil, "", err
+	}
+
 	user := model.User{
 		Name:     name,
 		Email:    email,
@@ -117,6 +162,10 @@ func RegisterUser(email, password, name string) (*model.Response, string, error)
 		return nil, "", err
 	}
 
+	if err := spendProofOfWork(proof); err != nil {
+		return nil, "", err
+	}
+
 	code := utils.GenerateCode()
 
 	err = smtp.App.SendConfirmationCodeEmail(user.Email, code, smtp.RegistrationCode)
@@ -191,9 +240,15 @@ func ConfirmRegistration(jwtToken, code string) (*model.Response, string, error)
 
 	newUser.Password = newPassword
 
-	res := db.App.Create(newUser)
-	if res.Error != nil {
-		return nil, "", res.Error
+	// Имя для упоминаний назначается по отображаемому имени, пользователь может сменить его позже
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newUser).Error; err != nil {
+			return err
+		}
+		return assignUsername(tx, newUser)
+	})
+	if err != nil {
+		return nil, "", err
 	}
 
 	token, err := CreateJWTToken(newUser)
@@ -222,6 +277,9 @@ func Login(email, password string) (*model.Response, string, error) {
 	if newPassword != account.Password {
 		return nil, "", fmt.Errorf("Неверный пароль")
 	}
+	if err := checkSuspended(&account); err != nil {
+		return nil, "", err
+	}
 
 	token, err := CreateJWTToken(&account)
 	if err != nil {
@@ -265,7 +323,12 @@ func JwtLogin(tokenString string) (*model.LoginResponse, error) {
 	}, nil
 }
 
-func ResetPassword(email string) (string, error) {
+// ResetPassword начинает сброс пароля: проверяет решение задачи proof-of-work и отправляет на почту код подтверждения
+func ResetPassword(email string, proof model.ProofOfWork) (string, error) {
+	if err := checkProofOfWork(model.PowResetPassword, email, proof); err != nil {
+		return "", err
+	}
+
 	err := utils.ValidateEmail(email)
 	if err != nil {
 		return "", err
@@ -276,6 +339,10 @@ func ResetPassword(email string) (string, error) {
 		return "", fmt.Errorf("Аккаунта с такой почтой не существует")
 	}
 
+	if err := spendProofOfWork(proof); err != nil {
+		return "", err
+	}
+
 	code := utils.GenerateCode()
 
 	err = smtp.App.SendConfirmationCodeEmail(email, code, smtp.PasswordResetCode)
@@ -389,11 +456,9 @@ func NewPost(jwtToken string, req model.NewPostRequest) (*model.NewPostResponse,
 		return nil, err
 	}
 
-	var tags []model.Tag
-	for _, tag := range req.Tags {
-		tags = append(tags, model.Tag{
-			Name: tag,
-		})
+	tags := normalizeTags(req.Tags)
+	if err := validatePostTags(token, tags, nil); err != nil {
+		return nil, err
 	}
 
 	post := model.Post{
@@ -401,12 +466,77 @@ func NewPost(jwtToken string, req model.NewPostRequest) (*model.NewPostResponse,
 		SubTitle: req.SubTitle,
 		Content:  req.Content,
 		AuthorID: token.UserId,
-		Tags:     tags,
 	}
 
-	res := db.App.Create(&post)
-	if res.Error != nil {
-		return nil, res.Error
+	if err := renderPost(db.App.DB, &post); err != nil {
+		return nil, err
+	}
+
+	// Пост, не прошедший фильтр, публикуется после проверки модератором
+	fingerprint, reasons, err := screenContent(db.App.DB, filter.TablePosts, 0, &user, postText(&post))
+	if err != nil {
+		return nil, err
+	}
+	post.Fingerprint = fingerprint
+	if len(reasons) > 0 {
+		post.Status = model.PostPending
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+
+		if err := assignSlug(tx, &post); err != nil {
+			return err
+		}
+
+		// Существующие теги переиспользуются, отсутствующие создаются
+		if err := replacePostTags(tx, &post, tags); err != nil {
+			return err
+		}
+
+		if _, err := savePostRevision(tx, &post, token.UserId, 0); err != nil {
+			return err
+		}
+
+		if err := savePostMedia(tx, post.ID, post.Content, post.AuthorID); err != nil {
+			return err
+		}
+
+		// Упомянутые в задержанном посте пользователи уведомляются при его публикации
+		if err := saveMentions(tx, post.ID, 0, post.Content, post.AuthorID); err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return holdContent(tx, model.ReportPost, post.ID, post.AuthorID, post.ID, reasons)
+		}
+		return notifyMentions(tx, post.ID, 0, post.AuthorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reasons) > 0 {
+		return &model.NewPostResponse{
+			Response: model.Response{
+				Status:  true,
+				Message: "Пост будет опубликован после проверки модератором",
+			},
+			ID: post.ID,
+		}, nil
+	}
+
+	// Клиенты живых обновлений получают пост в ленту, кроме пользователей, которые не видят посты автора
+	hiddenFrom, err := postHiddenFrom(post.AuthorID)
+	var feed []model.PostForFeed
+	if err == nil {
+		feed, err = postsToFeed([]model.Post{post}, 0)
+	}
+	if err == nil {
+		live.App.PublishNewPost(feed[0], hiddenFrom)
+	} else {
+		log.App.Error("Ошибка при отправке нового поста в живые обновления: ", err)
 	}
 
 	return &model.NewPostResponse{
@@ -418,6 +548,91 @@ func NewPost(jwtToken string, req model.NewPostRequest) (*model.NewPostResponse,
 	}, nil
 }
 
+// postToJson преобразует пост в формат ответа
+func postToJson(postDB *model.Post) model.PostJson {
+	ensureRendered(db.App.DB, postDB)
+
+	var tags []string
+	for _, tag := range postDB.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return model.PostJson{
+		ID:          postDB.ID,
+		Slug:        postDB.Slug,
+		URL:         postURL(postDB.Slug),
+		Title:       postDB.Title,
+		SubTitle:    postDB.SubTitle,
+		Content:     postDB.Content,
+		ContentHTML: postDB.ContentHTML,
+		WordCount:   postDB.WordCount,
+		ReadingTime: postDB.ReadingTime,
+		TOC:         postDB.TOC,
+		Tags:        tags,
+		Version:     postDB.Version,
+		CommentMode: postDB.CommentMode,
+		Status:      postDB.Status,
+	}
+}
+
+// renderPost рендерит содержание поста со ссылками на упомянутых пользователей и загруженные файлы
+// и пересчитывает сведения о тексте
+func renderPost(tx *gorm.DB, post *model.Post) error {
+	contentHTML, err := renderPostContent(tx, post.Content, post.AuthorID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при обработке содержания поста: %v", err)
+	}
+	post.ContentHTML = contentHTML
+	summarizePost(post)
+	return nil
+}
+
+// summarizePost пересчитывает отрывок, количество слов, время чтения и оглавление по отрендеренному содержанию
+func summarizePost(post *model.Post) {
+	conf := config.File.PostConfig
+	summary := markdown.Summarize(post.ContentHTML, conf.ExcerptLength)
+
+	post.Excerpt = summary.Excerpt
+	post.WordCount = summary.WordCount
+	post.ReadingTime = markdown.ReadingTime(summary.WordCount, conf.ReadingSpeed)
+	post.TOC = nil
+	for _, heading := range summary.Headings {
+		post.TOC = append(post.TOC, model.TOCEntry{
+			Level:  heading.Level,
+			Text:   heading.Text,
+			Anchor: heading.Anchor,
+		})
+	}
+}
+
+// ensureRendered дорабатывает посты, сохраненные до появления рендеринга и отрывков.
+// Такой пост обрабатывается один раз, результат сохраняется в БД.
+func ensureRendered(tx *gorm.DB, post *model.Post) {
+	if post.ReadingTime != 0 {
+		return
+	}
+
+	if post.ContentHTML == "" {
+		if err := renderPost(tx, post); err != nil {
+			log.App.Error("Ошибка при рендеринге поста ", post.ID, ": ", err)
+			return
+		}
+	} else {
+		summarizePost(post)
+	}
+
+	err := tx.Model(&model.Post{}).Where("id = ?", post.ID).UpdateColumns(map[string]interface{}{
+		"content_html": post.ContentHTML,
+		"excerpt":      post.Excerpt,
+		"word_count":   post.WordCount,
+		"reading_time": post.ReadingTime,
+		"toc":          post.TOC,
+	}).Error
+	if err != nil {
+		log.App.Error("Ошибка при сохранении отрендеренного поста ", post.ID, ": ", err)
+	}
+}
+
 func GetPost(jwtToken string, req model.GetPostRequest) (*model.GetPostResponse, error) {
 	log.App.Info("Попытка извлечения токена из заголовка.")
 	token, err := ParseJWTToken(jwtToken)
@@ -447,6 +662,10 @@ func GetPost(jwtToken string, req model.GetPostRequest) (*model.GetPostResponse,
 	}
 	log.App.Info("Пост успешно получен из базы данных: " + fmt.Sprintf("%+v", postDB))
 
+	if err := checkPostVisible(token, &postDB); err != nil {
+		return nil, err
+	}
+
 	// Проверяем права на редактирование
 	canEdit := false
 	if postDB.AuthorID == token.UserId {
@@ -457,17 +676,9 @@ func GetPost(jwtToken string, req model.GetPostRequest) (*model.GetPostResponse,
 		log.App.Info("Пользователь является администратором, редактирование разрешено.")
 	}
 
-	var tags []string
-	for _, tag := range postDB.Tags {
-		tags = append(tags, tag.Name)
-	}
-
-	post := model.PostJson{
-		ID:       postDB.ID,
-		Title:    postDB.Title,
-		SubTitle: postDB.SubTitle,
-		Content:  postDB.Content,
-		Tags:     tags,
+	post := postToJson(&postDB)
+	if err := fillReactions(&post, token.UserId); err != nil {
+		return nil, err
 	}
 
 	log.App.Info("Пост успешно сформирован для ответа: " + fmt.Sprintf("%+v", post))
@@ -497,7 +708,7 @@ func DeletePost(jwtToken string, req model.DeletePostRequest) (*model.DeletePost
 	}
 
 	var postDB model.Post
-	err = db.App.First(&postDB, req.ID).Error
+	err = db.App.Preload("Tags").First(&postDB, req.ID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -508,7 +719,13 @@ func DeletePost(jwtToken string, req model.DeletePostRequest) (*model.DeletePost
 			return nil, fmt.Errorf("У вас нет доступа к этому посту")
 		}
 	}
-	db.App.Delete(&postDB)
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		return trashPost(tx, &postDB)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return &model.DeletePostResponse{
 		Response: model.Response{
@@ -518,6 +735,28 @@ func DeletePost(jwtToken string, req model.DeletePostRequest) (*model.DeletePost
 	}, nil
 }
 
+// trashPost переносит пост в корзину: теги сохраняются в ревизии и отвязываются, лайки скрываются вместе с постом
+func trashPost(tx *gorm.DB, post *model.Post) error {
+	if err := ensureBaseRevision(tx, post); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&model.Like{}).Where("post_id = ?", post.ID).Update("deleted_at", now).Error; err != nil {
+		return fmt.Errorf("Ошибка при удалении лайков: %v", err)
+	}
+
+	// Связи с тегами остаются: удаленный пост не учитывается в тегах, но тег не считается неиспользуемым,
+	// а объединение и переименование тегов применяются и к нему
+
+	// Закладки остаются и показывают последний заголовок поста
+	if err := tx.Model(&model.Bookmark{}).Where("post_id = ?", post.ID).Update("post_title", post.Title).Error; err != nil {
+		return fmt.Errorf("Ошибка при обновлении закладок: %v", err)
+	}
+
+	return tx.Model(post).Update("deleted_at", now).Error
+}
+
 func UpdatePost(jwtToken string, req model.UpdatePostRequest) (*model.UpdatePostResponse, error) {
 	// Извлечение токена из заголовка
 	token, err := ParseJWTToken(jwtToken)
@@ -546,120 +785,138 @@ func UpdatePost(jwtToken string, req model.UpdatePostRequest) (*model.UpdatePost
 	}
 
 	// Проверка прав на изменение поста
-	if postDB.AuthorID != token.UserId {
-		if token.Role != model.AdminRole {
-			return nil, fmt.Errorf("У вас нет доступа к этому посту")
-		}
+	if !canEditPost(token, &postDB) {
+		return nil, fmt.Errorf("У вас нет доступа к этому посту")
 	}
 
-	// Обновление полей поста
-	postDB.Title = req.Post.Title
-	postDB.SubTitle = req.Post.SubTitle
-	postDB.Content = req.Post.Content
+	tags := normalizeTags(req.Post.Tags)
+	if err := validatePostTags(token, tags, postDB.Tags); err != nil {
+		return nil, err
+	}
 
-	// Обработка тегов
-	var newTags []model.Tag
-	for _, tagName := range req.Post.Tags {
-		var tag model.Tag
+	// Клиент, не передавший версию, сохраняет поверх прочитанной здесь версии
+	expectedVersion := req.Post.Version
+	if expectedVersion == 0 {
+		expectedVersion = postDB.Version
+	}
+	if expectedVersion != postDB.Version {
+		return nil, newPostConflictError(postDB.ID)
+	}
 
-		// Проверяем, существует ли тег в базе данных
-		err := db.App.Where("name = ?", tagName).First(&tag).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Если тег не существует, создаём его
-			tag = model.Tag{Name: tagName}
-			if err := db.App.Create(&tag).Error; err != nil {
-				return nil, fmt.Errorf("Ошибка при создании тега: %v", err)
-			}
-		} else if err != nil {
-			return nil, fmt.Errorf("Ошибка при проверке существования тега: %v", err)
+	// Опубликованный пост, не прошедший фильтр после изменения, снова ждет проверки модератором
+	updated := model.Post{Title: req.Post.Title, SubTitle: req.Post.SubTitle, Content: req.Post.Content}
+	fingerprint, reasons, err := screenContent(db.App.DB, filter.TablePosts, postDB.ID, &user, postText(&updated))
+	if err != nil {
+		return nil, err
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		// Пост мог быть создан до появления ревизий, сохраняем его исходное состояние
+		if err := ensureBaseRevision(tx, &postDB); err != nil {
+			return err
 		}
 
-		// Добавляем тег в список новых тегов
-		newTags = append(newTags, tag)
-	}
+		// Обновление полей поста
+		postDB.Title = req.Post.Title
+		postDB.SubTitle = req.Post.SubTitle
+		postDB.Content = req.Post.Content
+		postDB.Fingerprint = fingerprint
+		if err := renderPost(tx, &postDB); err != nil {
+			return err
+		}
 
-	// Удаляем старые связи
-	if err := db.App.Model(&postDB).Association("Tags").Clear(); err != nil {
-		return nil, fmt.Errorf("Ошибка при удалении старых тегов: %v", err)
-	}
+		// Сохраняем обновленный пост
+		if err := updatePostFields(tx, &postDB, expectedVersion); err != nil {
+			return err
+		}
+
+		// Slug меняется вместе с заголовком, прежний slug остается в истории
+		if err := assignSlug(tx, &postDB); err != nil {
+			return err
+		}
+
+		// Обработка тегов
+		if err := replacePostTags(tx, &postDB, tags); err != nil {
+			return err
+		}
+
+		if _, err := savePostRevision(tx, &postDB, token.UserId, 0); err != nil {
+			return err
+		}
 
-	// Привязываем новые теги
-	if err := db.App.Model(&postDB).Association("Tags").Append(newTags); err != nil {
-		return nil, fmt.Errorf("Ошибка при добавлении новых тегов: %v", err)
+		if err := savePostMedia(tx, postDB.ID, postDB.Content, postDB.AuthorID); err != nil {
+			return err
+		}
+
+		// Уведомляются только пользователи, впервые упомянутые в посте
+		if err := saveMentions(tx, postDB.ID, 0, postDB.Content, postDB.AuthorID); err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			if postDB.Status == model.PostPublished {
+				postDB.Status = model.PostPending
+				if err := tx.Model(&postDB).UpdateColumn("status", postDB.Status).Error; err != nil {
+					return err
+				}
+			}
+			return holdContent(tx, model.ReportPost, postDB.ID, postDB.AuthorID, postDB.ID, reasons)
+		}
+		if postDB.Status != model.PostPublished {
+			return nil
+		}
+		return notifyMentions(tx, postDB.ID, 0, postDB.AuthorID)
+	})
+	if errors.Is(err, errVersionConflict) {
+		return nil, newPostConflictError(postDB.ID)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Сохраняем обновленный пост
-	if err := db.App.Save(&postDB).Error; err != nil {
-		return nil, fmt.Errorf("Ошибка при сохранении поста: %v", err)
+	message := "Пост обновлен"
+	if len(reasons) > 0 {
+		message = "Пост обновлен и будет опубликован после проверки модератором"
 	}
 
 	return &model.UpdatePostResponse{
 		Response: model.Response{
 			Status:  true,
-			Message: "Пост обновлен",
+			Message: message,
 		},
+		Version: postDB.Version,
 	}, nil
 }
 
-func GetAllPosts(req model.GetAllPostsRequest) (*model.GetAllPostsResponse, error) {
+// GetAllPosts возвращает ленту всех постов. Блокировки, скрытые авторы, подписки на теги, реакции и закладки
+// учитываются для пользователя из токена, токен необязателен.
+func GetAllPosts(jwtToken string, req model.GetAllPostsRequest) (*model.GetAllPostsResponse, error) {
 	log.App.Info("Попытка получения всех постов.")
+	viewerID := optionalUserID(jwtToken)
 
+	// Посты заблокированных и скрытых пользователем авторов в ленту не попадают.
+	// Теги, на которые подписан пользователь, поднимают посты только в пределах страницы.
+	_, limit, offset := pagination(req.Page, req.Limit)
 	var posts []model.Post
-	err := db.App.Preload("Tags").Find(&posts).Error
+	err := visiblePosts(db.App.Preload("Tags"), viewerID).
+		Order("posts.created_at DESC, posts.id DESC").Offset(offset).Limit(limit).Find(&posts).Error
 	if err != nil {
 		log.App.Error("Ошибка при получении постов: " + err.Error())
 		return nil, err
 	}
 	log.App.Info("Посты успешно получены из базы данных.")
 
-	var postResponses []model.PostForFeed
-	for _, postDB := range posts {
-		var tags []string
-		for _, tag := range postDB.Tags {
-			tags = append(tags, tag.Name)
-		}
-
-		// Устанавливаем значение InitialLiked
-		initialLiked := false
-		if req.ID != 0 {
-			// Получаем лайки пользователя только если ID не равен 0
-			var userLikes []model.Like
-			err = db.App.Where("user_id = ?", req.ID).Find(&userLikes).Error
-			if err != nil {
-				log.App.Error("Ошибка при получении лайков пользователя: " + err.Error())
-				return nil, err
-			}
-
-			// Создаем мапу для быстрого поиска лайков
-			likedPosts := make(map[uint]bool)
-			for _, like := range userLikes {
-				likedPosts[like.PostID] = true
-			}
-
-			// Проверяем, лайкнул ли пользователь пост
-			initialLiked = likedPosts[postDB.ID]
-		}
-
-		var user model.User
-		err = db.App.First(&user, postDB.AuthorID).Error
-		if err != nil {
-			log.App.Error("Ошибка при получении пользователя: " + err.Error())
+	// Посты с тегами, на которые подписан пользователь, поднимаются выше
+	if viewerID != 0 {
+		if err := boostFollowedTags(posts, viewerID); err != nil {
+			log.App.Error("Ошибка при получении подписок на теги: " + err.Error())
 			return nil, err
 		}
+	}
 
-		postResponse := model.PostForFeed{
-			ID:           postDB.ID,
-			Title:        postDB.Title,
-			SubTitle:     postDB.SubTitle,
-			Content:      postDB.Content,
-			Tags:         tags,
-			AuthorName:   user.Name,
-			Likes:        postDB.LikesCount,
-			AuthorId:     postDB.AuthorID,
-			InitialLiked: initialLiked,
-			Date:         postDB.CreatedAt.Format("02.01.2006"),
-		}
-		postResponses = append(postResponses, postResponse)
+	postResponses, err := postsToFeed(posts, viewerID)
+	if err != nil {
+		log.App.Error("Ошибка при формировании ленты: " + err.Error())
+		return nil, err
 	}
 
 	return &model.GetAllPostsResponse{
@@ -669,66 +926,37 @@ func GetAllPosts(req model.GetAllPostsRequest) (*model.GetAllPostsResponse, erro
 	}, nil
 }
 
-func GetAllMyPosts(req model.GetAllPostsRequest) (*model.GetAllPostsResponse, error) {
-	log.App.Info("Попытка получения постов для пользователя с ID: ", req.ID)
+// GetAllMyPosts возвращает посты автора с ID из запроса, по умолчанию - пользователя из токена. Свои посты автор
+// видит все, включая ожидающие проверки и скрытые модератором, у чужого автора видны только опубликованные посты
+// с учетом блокировок и скрытых авторов. Токен необязателен.
+func GetAllMyPosts(jwtToken string, req model.GetAllPostsRequest) (*model.GetAllPostsResponse, error) {
+	viewerID := optionalUserID(jwtToken)
+	authorID := req.ID
+	if authorID == 0 {
+		authorID = viewerID
+	}
+	if authorID == 0 {
+		return nil, fmt.Errorf("Не указан автор")
+	}
+	log.App.Info("Попытка получения постов для пользователя с ID: ", authorID)
 
 	var posts []model.Post
-	// Изменяем запрос, чтобы получить только посты текущего пользователя
-	err := db.App.Preload("Tags").Where("author_id = ?", req.ID).Find(&posts).Error
+	// Изменяем запрос, чтобы получить только посты выбранного автора
+	query := db.App.Preload("Tags").Where("posts.author_id = ?", authorID)
+	if authorID != viewerID {
+		query = visiblePosts(query, viewerID)
+	}
+	err := query.Find(&posts).Error
 	if err != nil {
 		log.App.Error("Ошибка при получении постов: " + err.Error())
 		return nil, err
 	}
 	log.App.Info("Посты успешно получены из базы данных.")
 
-	var postResponses []model.PostForFeed
-	for _, postDB := range posts {
-		var tags []string
-		for _, tag := range postDB.Tags {
-			tags = append(tags, tag.Name)
-		}
-
-		// Устанавливаем значение InitialLiked
-		initialLiked := false
-		if req.ID != 0 {
-			// Получаем лайки пользователя только если ID не равен 0
-			var userLikes []model.Like
-			err = db.App.Where("user_id = ?", req.ID).Find(&userLikes).Error
-			if err != nil {
-				log.App.Error("Ошибка при получении лайков пользователя: " + err.Error())
-				return nil, err
-			}
-
-			// Создаем мапу для быстрого поиска лайков
-			likedPosts := make(map[uint]bool)
-			for _, like := range userLikes {
-				likedPosts[like.PostID] = true
-			}
-
-			// Проверяем, лайкнул ли пользователь пост
-			initialLiked = likedPosts[postDB.ID]
-		}
-
-		var user model.User
-		err = db.App.First(&user, postDB.AuthorID).Error
-		if err != nil {
-			log.App.Error("Ошибка при получении пользователя: " + err.Error())
-			return nil, err
-		}
-
-		postResponse := model.PostForFeed{
-			ID:           postDB.ID,
-			Title:        postDB.Title,
-			SubTitle:     postDB.SubTitle,
-			Content:      postDB.Content,
-			Tags:         tags,
-			Likes:        postDB.LikesCount,
-			AuthorId:     postDB.AuthorID,
-			InitialLiked: initialLiked,
-			AuthorName:   user.Name,
-			Date:         postDB.CreatedAt.Format("02.01.2006"),
-		}
-		postResponses = append(postResponses, postResponse)
+	postResponses, err := postsToFeed(posts, viewerID)
+	if err != nil {
+		log.App.Error("Ошибка при формировании ленты: " + err.Error())
+		return nil, err
 	}
 
 	return &model.GetAllPostsResponse{
@@ -738,7 +966,7 @@ func GetAllMyPosts(req model.GetAllPostsRequest) (*model.GetAllPostsResponse, er
 	}, nil
 }
 
-// PutLike обрабатывает запрос на постановку лайка
+// PutLike обрабатывает запрос на постановку лайка. Лайк - реакция вида model.ReactionLike.
 func PutLike(tokenString string, req model.LikeRequest) (*model.LikeResponse, error) {
 	// Извлечение токена из заголовка
 	token, err := ParseJWTToken(tokenString)
@@ -746,7 +974,7 @@ func PutLike(tokenString string, req model.LikeRequest) (*model.LikeResponse, er
 		return nil, err
 	}
 	// Логгируем данные запроса с информацией о пользователе и посте
-	log.App.Info(fmt.Sprintf("Пользователь с ID %d ставит лайк на пост с ID %d", token.UserId, req.PostID))
+	log.App.Info(fmt.Sprintf("Пользователь с ID %d ставит лайк на пост с ID %s", token.UserId, req.PostID))
 
 	// Преобразуем PostID из string в uint
 	postID, err := strconv.ParseUint(req.PostID, 10, 32)
@@ -755,44 +983,12 @@ func PutLike(tokenString string, req model.LikeRequest) (*model.LikeResponse, er
 		return nil, fmt.Errorf("недопустимый ID поста")
 	}
 
-	// Проверяем, существует ли уже лайк для данного пользователя и поста
-	var existingLike model.Like
-	err = db.App.Where("user_id = ? AND post_id = ?", token.UserId, uint(postID)).First(&existingLike).Error
-	if err == nil {
-		// Если лайк уже существует, возвращаем ошибку
-		return nil, fmt.Errorf("Лайк уже поставлен для этого поста")
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// Если произошла другая ошибка
-		log.App.Error("Ошибка при проверке существования лайка: ", err)
-		return nil, err
-	}
-
-	// Создаем новый лайк
-	newLike := model.Like{
-		UserID: token.UserId,
-		PostID: uint(postID), // Используем преобразованный ID
-	}
-
-	if err := db.App.Create(&newLike).Error; err != nil {
-		log.App.Error("Ошибка при создании лайка: ", err)
+	if err := putReaction(token.UserId, uint(postID), model.ReactionLike); err != nil {
 		return nil, err
 	}
 
 	log.App.Info("Лайк успешно поставлен для поста с ID: ", req.PostID)
 
-	// Обновляем количество лайков в посте
-	var post model.Post
-	if err := db.App.First(&post, uint(postID)).Error; err != nil {
-		log.App.Error("Ошибка при получении поста: ", err)
-		return nil, err
-	}
-
-	post.LikesCount++
-	if err := db.App.Save(&post).Error; err != nil {
-		log.App.Error("Ошибка при обновлении количества лайков: ", err)
-		return nil, err
-	}
-
 	return &model.LikeResponse{
 		Response: model.Response{
 			Status:  true,
@@ -810,7 +1006,7 @@ func DownLike(tokenString string, req model.LikeRequest) (*model.LikeResponse, e
 		return nil, err
 	}
 	// Логгируем данные запроса с информацией о пользователе и посте
-	log.App.Info(fmt.Sprintf("Пользователь с ID %d снимает лайк с поста с ID %d", token.UserId, req.PostID))
+	log.App.Info(fmt.Sprintf("Пользователь с ID %d снимает лайк с поста с ID %s", token.UserId, req.PostID))
 	// Преобразуем PostID из string в uint
 	postID, err := strconv.ParseUint(req.PostID, 10, 32)
 	if err != nil {
@@ -818,43 +1014,12 @@ func DownLike(tokenString string, req model.LikeRequest) (*model.LikeResponse, e
 		return nil, fmt.Errorf("недопустимый ID поста")
 	}
 
-	// Проверяем, существует ли лайк для данного пользователя и поста
-	var existingLike model.Like
-	err = db.App.Where("user_id = ? AND post_id = ?", token.UserId, uint(postID)).First(&existingLike).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Если лайк не найден, возвращаем ошибку
-			return nil, fmt.Errorf("Лайк не найден для этого поста")
-		}
-		log.App.Error("Ошибка при проверке существования лайка: ", err)
-		return nil, err
-	}
-
-	// Удаляем лайк
-	if err := db.App.Delete(&existingLike).Error; err != nil {
-		log.App.Error("Ошибка при удалении лайка: ", err)
+	if err := removeReaction(token.UserId, uint(postID), model.ReactionLike); err != nil {
 		return nil, err
 	}
 
 	log.App.Info("Лайк успешно снят для поста с ID: ", req.PostID)
 
-	// Обновляем количество лайков в посте
-	var post model.Post
-	if err := db.App.First(&post, uint(postID)).Error; err != nil {
-		log.App.Error("Ошибка при получении поста: ", err)
-		return nil, err
-	}
-
-	post.LikesCount--
-	if post.LikesCount < 0 {
-		post.LikesCount = 0 // Убедимся, что количество лайков не становится отрицательным
-	}
-
-	if err := db.App.Save(&post).Error; err != nil {
-		log.App.Error("Ошибка при обновлении количества лайков: ", err)
-		return nil, err
-	}
-
 	return &model.LikeResponse{
 		Response: model.Response{
 			Status:  true,
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// checkNotBlocked проверяет, что пользователи actorID и otherID не заблокировали друг друга.
+// Используется перед действиями actorID, затрагивающими otherID: реакциями, комментариями и подписками.
+func checkNotBlocked(actorID, otherID uint) error {
+	if actorID == 0 || otherID == 0 || actorID == otherID {
+		return nil
+	}
+
+	var blocks []model.Block
+	err := db.App.Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+		actorID, otherID, otherID, actorID).Find(&blocks).Error
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if block.BlockerID == otherID {
+			return fmt.Errorf("Пользователь ограничил вам доступ")
+		}
+	}
+	if len(blocks) > 0 {
+		return fmt.Errorf("Вы заблокировали этого пользователя")
+	}
+	return nil
+}
+
+// postHiddenFrom возвращает пользователей, которым не показываются новые посты автора authorID
+func postHiddenFrom(authorID uint) ([]uint, error) {
+	var userIDs []uint
+	err := db.App.Raw(`SELECT blocker_id FROM blocks WHERE blocked_id = ?
+		UNION SELECT blocked_id FROM blocks WHERE blocker_id = ?
+		UNION SELECT muter_id FROM mutes WHERE muted_id = ?`, authorID, authorID, authorID).Scan(&userIDs).Error
+	return userIDs, err
+}
+
+// findRestrictTarget проверяет пользователя, которого владелец токена блокирует или скрывает
+func findRestrictTarget(token *model.Token, userID uint) error {
+	if userID == token.UserId {
+		return fmt.Errorf("Нельзя ограничить самого себя")
+	}
+	if err := db.App.First(&model.User{}, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Пользователь не найден")
+		}
+		return err
+	}
+	return nil
+}
+
+// BlockUser блокирует пользователя. Подписки пользователей друг на друга удаляются. Повторная блокировка не считается ошибкой.
+func BlockUser(jwtToken string, req model.RestrictUserRequest) (*model.RestrictUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := findRestrictTarget(token, req.UserID); err != nil {
+		return nil, err
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		block := model.Block{BlockerID: token.UserId, BlockedID: req.UserID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)",
+			token.UserId, req.UserID, req.UserID, token.UserId).Delete(&model.Follow{}).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при блокировке пользователя: ", err)
+		return nil, err
+	}
+
+	return &model.RestrictUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пользователь заблокирован",
+		},
+	}, nil
+}
+
+// UnblockUser снимает блокировку пользователя. Удаленные при блокировке подписки не восстанавливаются.
+func UnblockUser(jwtToken string, req model.RestrictUserRequest) (*model.RestrictUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("blocker_id = ? AND blocked_id = ?", token.UserId, req.UserID).Delete(&model.Block{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при разблокировке пользователя: ", err)
+		return nil, err
+	}
+
+	return &model.RestrictUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пользователь разблокирован",
+		},
+	}, nil
+}
+
+// MuteUser скрывает посты пользователя из лент владельца токена. Повторное скрытие не считается ошибкой.
+func MuteUser(jwtToken string, req model.RestrictUserRequest) (*model.RestrictUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := findRestrictTarget(token, req.UserID); err != nil {
+		return nil, err
+	}
+
+	mute := model.Mute{MuterID: token.UserId, MutedID: req.UserID}
+	if err := db.App.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		log.App.Error("Ошибка при скрытии пользователя: ", err)
+		return nil, err
+	}
+
+	return &model.RestrictUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пользователь скрыт",
+		},
+	}, nil
+}
+
+// UnmuteUser возвращает посты пользователя в ленты владельца токена
+func UnmuteUser(jwtToken string, req model.RestrictUserRequest) (*model.RestrictUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("muter_id = ? AND muted_id = ?", token.UserId, req.UserID).Delete(&model.Mute{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при отмене скрытия пользователя: ", err)
+		return nil, err
+	}
+
+	return &model.RestrictUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пользователь больше не скрыт",
+		},
+	}, nil
+}
+
+// restrictedUsers возвращает пользователей из таблицы table, ограниченных пользователем userID, начиная с последних.
+// ownerColumn - столбец владельца ограничения, targetColumn - столбец ограниченного пользователя.
+func restrictedUsers(table, ownerColumn, targetColumn string, userID uint) ([]model.RestrictedUserJson, error) {
+	var rows []struct {
+		ID           uint
+		Name         string
+		Username     string
+		RestrictedAt time.Time
+	}
+	err := db.App.Table(table).
+		Select("users.id, users.name, users.username, "+table+".created_at AS restricted_at").
+		Joins("JOIN users ON users.id = "+table+"."+targetColumn).
+		Where(table+"."+ownerColumn+" = ? AND users.deleted_at IS NULL", userID).
+		Order(table + ".created_at DESC, " + table + ".id DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]model.RestrictedUserJson, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, model.RestrictedUserJson{
+			ID:       row.ID,
+			Name:     row.Name,
+			Username: row.Username,
+			Date:     row.RestrictedAt.Format("02.01.2006"),
+		})
+	}
+	return users, nil
+}
+
+// GetBlockedUsers возвращает пользователей, заблокированных владельцем токена
+func GetBlockedUsers(jwtToken string) (*model.GetRestrictedUsersResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := restrictedUsers("blocks", "blocker_id", "blocked_id", token.UserId)
+	if err != nil {
+		log.App.Error("Ошибка при получении заблокированных пользователей: ", err)
+		return nil, err
+	}
+
+	return &model.GetRestrictedUsersResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Заблокированные пользователи получены",
+		},
+		Users: users,
+	}, nil
+}
+
+// GetMutedUsers возвращает пользователей, скрытых владельцем токена
+func GetMutedUsers(jwtToken string) (*model.GetRestrictedUsersResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := restrictedUsers("mutes", "muter_id", "muted_id", token.UserId)
+	if err != nil {
+		log.App.Error("Ошибка при получении скрытых пользователей: ", err)
+		return nil, err
+	}
+
+	return &model.GetRestrictedUsersResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Скрытые пользователи получены",
+		},
+		Users: users,
+	}, nil
+}
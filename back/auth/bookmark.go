@@ -0,0 +1,476 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/markdown"
+	"app/model"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	savedListName         = "Сохраненное" // Название списка закладок без списка чтения
+	maxReadingListNameLen = 100           // Максимальная длина названия списка чтения
+)
+
+// bookmarkedPosts возвращает посты из postIDs, которые пользователь viewerID добавил в закладки
+func bookmarkedPosts(postIDs []uint, viewerID uint) (map[uint]bool, error) {
+	bookmarked := make(map[uint]bool)
+	if viewerID == 0 {
+		return bookmarked, nil
+	}
+
+	var ids []uint
+	err := db.App.Model(&model.Bookmark{}).Where("user_id = ? AND post_id IN ?", viewerID, postIDs).Pluck("post_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении закладок пользователя: %v", err)
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+// getOwnReadingList возвращает список чтения пользователя. Для списка "Сохраненное" (listID = 0) возвращается nil.
+func getOwnReadingList(userID, listID uint) (*model.ReadingList, error) {
+	if listID == 0 {
+		return nil, nil
+	}
+
+	var list model.ReadingList
+	if err := db.App.Where("id = ? AND user_id = ?", listID, userID).First(&list).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Список чтения не найден")
+		}
+		return nil, err
+	}
+	return &list, nil
+}
+
+// nextBookmarkPosition возвращает позицию для закладки, добавляемой в конец списка
+func nextBookmarkPosition(tx *gorm.DB, userID, listID uint) (int, error) {
+	var position int
+	err := tx.Model(&model.Bookmark{}).Select("COALESCE(MAX(position), -1) + 1").
+		Where("user_id = ? AND list_id = ?", userID, listID).Scan(&position).Error
+	return position, err
+}
+
+// countBookmarks возвращает количество закладок пользователя по спискам
+func countBookmarks(userID uint) (map[uint]int64, error) {
+	var counts []struct {
+		ListID uint
+		Count  int64
+	}
+	err := db.App.Model(&model.Bookmark{}).Select("list_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).Group("list_id").Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]int64, len(counts))
+	for _, count := range counts {
+		result[count.ListID] = count.Count
+	}
+	return result, nil
+}
+
+// readingListToJson преобразует список чтения в формат ответа. Для nil возвращается список "Сохраненное".
+func readingListToJson(list *model.ReadingList, count int64) model.ReadingListJson {
+	if list == nil {
+		return model.ReadingListJson{Name: savedListName, Count: count}
+	}
+	return model.ReadingListJson{
+		ID:     list.ID,
+		Name:   list.Name,
+		Public: list.Public,
+		Count:  count,
+	}
+}
+
+// normalizeReadingListName убирает из названия списка разметку и лишние пробелы и проверяет его длину
+func normalizeReadingListName(name string) (string, error) {
+	name = strings.Join(strings.Fields(markdown.App.PlainText(name)), " ")
+	if name == "" {
+		return "", fmt.Errorf("Название списка не может быть пустым")
+	}
+	if utf8.RuneCountInString(name) > maxReadingListNameLen {
+		return "", fmt.Errorf("Название списка длиннее %d символов", maxReadingListNameLen)
+	}
+	return name, nil
+}
+
+// AddBookmark добавляет пост в закладки. Если пост уже в закладках, он переносится в конец указанного списка.
+func AddBookmark(jwtToken string, req model.AddBookmarkRequest) (*model.AddBookmarkResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var post model.Post
+	if err := db.App.Select("id, title, status, author_id").First(&post, req.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пост не найден")
+		}
+		return nil, err
+	}
+	// Название сохраняется в закладке, поэтому недоступный пользователю пост добавить нельзя
+	if err := checkPostVisible(token, &post); err != nil {
+		return nil, err
+	}
+	if _, err := getOwnReadingList(token.UserId, req.ListID); err != nil {
+		return nil, err
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		var bookmark model.Bookmark
+		err := tx.Where("user_id = ? AND post_id = ?", token.UserId, post.ID).First(&bookmark).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && bookmark.ListID == req.ListID {
+			return nil
+		}
+
+		position, err := nextBookmarkPosition(tx, token.UserId, req.ListID)
+		if err != nil {
+			return err
+		}
+
+		if bookmark.ID != 0 {
+			return tx.Model(&bookmark).Updates(map[string]interface{}{
+				"list_id":  req.ListID,
+				"position": position,
+			}).Error
+		}
+
+		bookmark = model.Bookmark{
+			UserID:    token.UserId,
+			PostID:    post.ID,
+			ListID:    req.ListID,
+			Position:  position,
+			PostTitle: post.Title,
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при добавлении закладки: ", err)
+		return nil, err
+	}
+
+	return &model.AddBookmarkResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пост добавлен в закладки",
+		},
+	}, nil
+}
+
+// RemoveBookmark удаляет пост из закладок. Удалить можно и закладку удаленного поста.
+func RemoveBookmark(jwtToken string, req model.RemoveBookmarkRequest) (*model.RemoveBookmarkResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("user_id = ? AND post_id = ?", token.UserId, req.PostID).Delete(&model.Bookmark{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при удалении закладки: ", err)
+		return nil, err
+	}
+
+	return &model.RemoveBookmarkResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пост удален из закладок",
+		},
+	}, nil
+}
+
+// CreateReadingList создает список чтения
+func CreateReadingList(jwtToken string, req model.SaveReadingListRequest) (*model.SaveReadingListResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeReadingListName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := model.ReadingList{UserID: token.UserId, Name: name, Public: req.Public}
+	if err := db.App.Create(&list).Error; err != nil {
+		log.App.Error("Ошибка при создании списка чтения: ", err)
+		return nil, err
+	}
+
+	return &model.SaveReadingListResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Список чтения создан",
+		},
+		List: readingListToJson(&list, 0),
+	}, nil
+}
+
+// UpdateReadingList переименовывает список чтения и меняет его видимость
+func UpdateReadingList(jwtToken string, req model.SaveReadingListRequest) (*model.SaveReadingListResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.ID == 0 {
+		return nil, fmt.Errorf("Список \"%s\" нельзя изменить", savedListName)
+	}
+
+	list, err := getOwnReadingList(token.UserId, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := normalizeReadingListName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Model(list).Updates(map[string]interface{}{
+		"name":   name,
+		"public": req.Public,
+	}).Error
+	if err != nil {
+		log.App.Error("Ошибка при изменении списка чтения: ", err)
+		return nil, err
+	}
+
+	counts, err := countBookmarks(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SaveReadingListResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Список чтения изменен",
+		},
+		List: readingListToJson(list, counts[list.ID]),
+	}, nil
+}
+
+// DeleteReadingList удаляет список чтения. Закладки из него переносятся в конец списка "Сохраненное".
+func DeleteReadingList(jwtToken string, req model.DeleteReadingListRequest) (*model.DeleteReadingListResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.ID == 0 {
+		return nil, fmt.Errorf("Список \"%s\" нельзя удалить", savedListName)
+	}
+
+	list, err := getOwnReadingList(token.UserId, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		position, err := nextBookmarkPosition(tx, token.UserId, 0)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&model.Bookmark{}).Where("user_id = ? AND list_id = ?", token.UserId, list.ID).
+			Updates(map[string]interface{}{
+				"list_id":  0,
+				"position": gorm.Expr("position + ?", position),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(list).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при удалении списка чтения: ", err)
+		return nil, err
+	}
+
+	return &model.DeleteReadingListResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Список чтения удален",
+		},
+	}, nil
+}
+
+// ReorderReadingList меняет порядок закладок в списке чтения
+func ReorderReadingList(jwtToken string, req model.ReorderReadingListRequest) (*model.ReorderReadingListResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getOwnReadingList(token.UserId, req.ListID); err != nil {
+		return nil, err
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		var bookmarks []model.Bookmark
+		err := tx.Where("user_id = ? AND list_id = ?", token.UserId, req.ListID).Order("position, id").Find(&bookmarks).Error
+		if err != nil {
+			return err
+		}
+
+		// Сначала идут посты в указанном порядке, затем остальные в прежнем порядке
+		order := make(map[uint]int, len(req.PostIDs))
+		for _, postID := range req.PostIDs {
+			if _, ok := order[postID]; !ok {
+				order[postID] = len(order)
+			}
+		}
+		rest := len(order)
+		for _, bookmark := range bookmarks {
+			position, ok := order[bookmark.PostID]
+			if !ok {
+				position = rest
+				rest++
+			}
+			if position == bookmark.Position {
+				continue
+			}
+			if err := tx.Model(&bookmark).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.App.Error("Ошибка при изменении порядка закладок: ", err)
+		return nil, err
+	}
+
+	return &model.ReorderReadingListResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Порядок закладок изменен",
+		},
+	}, nil
+}
+
+// GetReadingLists возвращает списки чтения пользователя, начиная со списка "Сохраненное"
+func GetReadingLists(jwtToken string) (*model.GetReadingListsResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists []model.ReadingList
+	if err := db.App.Where("user_id = ?", token.UserId).Order("created_at, id").Find(&lists).Error; err != nil {
+		log.App.Error("Ошибка при получении списков чтения: ", err)
+		return nil, err
+	}
+	counts, err := countBookmarks(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.ReadingListJson, 0, len(lists)+1)
+	result = append(result, readingListToJson(nil, counts[0]))
+	for i := range lists {
+		result = append(result, readingListToJson(&lists[i], counts[lists[i].ID]))
+	}
+
+	return &model.GetReadingListsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Списки чтения получены",
+		},
+		Lists: result,
+	}, nil
+}
+
+// GetReadingList возвращает страницу закладок списка чтения. Токен необязателен: публичный список
+// доступен всем, закрытый и список "Сохраненное" - только владельцу. Удаленные, скрытые и недоступные читателю
+// из-за блокировок посты показываются заглушками, название в заглушке видит только владелец списка.
+func GetReadingList(jwtToken string, req model.GetReadingListRequest) (*model.GetReadingListResponse, error) {
+	viewerID := optionalUserID(jwtToken)
+
+	ownerID := viewerID
+	var list *model.ReadingList
+	if req.ListID != 0 {
+		list = &model.ReadingList{}
+		if err := db.App.First(list, req.ListID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if list.ID == 0 || (!list.Public && list.UserID != viewerID) {
+			return nil, fmt.Errorf("Список чтения не найден")
+		}
+		ownerID = list.UserID
+	} else if viewerID == 0 {
+		return nil, fmt.Errorf("Список чтения не найден")
+	}
+
+	query := db.App.Model(&model.Bookmark{}).Where("user_id = ? AND list_id = ?", ownerID, req.ListID)
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var bookmarks []model.Bookmark
+	if err := query.Order("position, id").Offset(offset).Limit(limit).Find(&bookmarks).Error; err != nil {
+		log.App.Error("Ошибка при получении закладок: ", err)
+		return nil, err
+	}
+
+	postIDs := make([]uint, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		postIDs = append(postIDs, bookmark.PostID)
+	}
+	var posts []model.Post
+	// Скрытые модерацией посты и посты заблокированных авторов показываются в закладках так же, как удаленные
+	if err := visiblePosts(db.App.Preload("Tags").Where("posts.id IN ?", postIDs), viewerID).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	own := viewerID != 0 && ownerID == viewerID
+	feed, err := postsToFeed(posts, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	feedByID := make(map[uint]*model.PostForFeed, len(feed))
+	for i := range feed {
+		feedByID[feed[i].ID] = &feed[i]
+	}
+
+	result := make([]model.BookmarkJson, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		item := model.BookmarkJson{
+			PostID: bookmark.PostID,
+			Title:  bookmark.PostTitle,
+			Date:   bookmark.CreatedAt.Format("02.01.2006"),
+		}
+		if post, ok := feedByID[bookmark.PostID]; ok {
+			item.Title = post.Title
+			item.Post = post
+		} else {
+			item.Deleted = true
+			if !own {
+				item.Title = ""
+			}
+		}
+		result = append(result, item)
+	}
+
+	return &model.GetReadingListResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Список чтения получен",
+		},
+		List:      readingListToJson(list, total),
+		Own:       own,
+		Bookmarks: result,
+		Page:      page,
+		Total:     total,
+	}, nil
+}
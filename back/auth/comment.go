@@ -0,0 +1,552 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/filter"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// commentEditWindow возвращает время, в течение которого автор может изменить комментарий
+func commentEditWindow() time.Duration {
+	return time.Duration(config.File.CommentConfig.EditWindow) * time.Minute
+}
+
+// renderComment проверяет текст комментария автора authorID и рендерит его так же, как содержание поста
+func renderComment(body string, authorID uint) (string, string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", "", fmt.Errorf("Комментарий не может быть пустым")
+	}
+	if maxLength := config.File.CommentConfig.MaxLength; utf8.RuneCountInString(body) > maxLength {
+		return "", "", fmt.Errorf("Комментарий длиннее %d символов", maxLength)
+	}
+
+	bodyHTML, err := renderMentions(db.App.DB, body, authorID)
+	if err != nil {
+		return "", "", fmt.Errorf("Ошибка при обработке комментария: %v", err)
+	}
+	return body, bodyHTML, nil
+}
+
+// canEditComment проверяет, может ли пользователь изменить комментарий: только автор и только в течение окна редактирования
+func canEditComment(userID uint, comment *model.Comment) bool {
+	return comment.AuthorID == userID && comment.Status != model.CommentRejected && time.Since(comment.CreatedAt) <= commentEditWindow()
+}
+
+// canDeleteComment проверяет, может ли владелец токена удалить комментарий.
+// Автор поста и администратор могут удалить любой комментарий к посту.
+func canDeleteComment(token *model.Token, comment *model.Comment, post *model.Post) bool {
+	return comment.AuthorID == token.UserId || canEditPost(token, post)
+}
+
+// canModerateComments проверяет, может ли владелец токена модерировать комментарии к посту:
+// автор поста, администратор или модератор
+func canModerateComments(token *model.Token, post *model.Post) bool {
+	return token != nil && (canEditPost(token, post) || token.Role == model.ModeratorRole)
+}
+
+// commentVisible проверяет, виден ли комментарий владельцу токена. Ожидающий одобрения комментарий
+// видят его автор и модераторы поста, задержанный фильтром - его автор, администраторы и модераторы,
+// отклоненный и удаленный - никто.
+func commentVisible(comment *model.Comment, token *model.Token, post *model.Post) bool {
+	if comment.DeletedAt.Valid {
+		return false
+	}
+	switch comment.Status {
+	case model.CommentApproved:
+		return true
+	case model.CommentPending:
+		return canModerateComments(token, post) || (token != nil && token.UserId == comment.AuthorID)
+	case model.CommentHeld:
+		return canModerate(token) || (token != nil && token.UserId == comment.AuthorID)
+	default:
+		return false
+	}
+}
+
+// visibleCommentsSQL возвращает SQL-условие commentVisible для таблицы комментариев с псевдонимом alias
+func visibleCommentsSQL(alias string, token *model.Token, post *model.Post) (string, []interface{}) {
+	condition := alias + ".deleted_at IS NULL AND (" + alias + ".status = ?"
+	args := []interface{}{model.CommentApproved}
+	if canModerateComments(token, post) {
+		condition += " OR " + alias + ".status = ?"
+		args = append(args, model.CommentPending)
+	}
+	if canModerate(token) {
+		condition += " OR " + alias + ".status = ?"
+		args = append(args, model.CommentHeld)
+	}
+	if token != nil {
+		condition += " OR (" + alias + ".status IN ? AND " + alias + ".author_id = ?)"
+		args = append(args, []string{model.CommentPending, model.CommentHeld}, token.UserId)
+	}
+	return condition + ")", args
+}
+
+// commentToJson преобразует комментарий в формат ответа. token может быть nil для анонимного пользователя.
+func commentToJson(comment *model.Comment, authorName string, token *model.Token, post *model.Post) model.CommentJson {
+	result := model.CommentJson{
+		ID:         comment.ID,
+		ParentID:   comment.ParentID,
+		AuthorID:   comment.AuthorID,
+		AuthorName: authorName,
+		Body:       comment.Body,
+		BodyHTML:   comment.BodyHTML,
+		Date:       comment.CreatedAt.Format("02.01.2006 15:04"),
+		Edited:     comment.EditedAt != nil,
+		Pending:    comment.Status == model.CommentPending || comment.Status == model.CommentHeld,
+		Locked:     comment.Locked,
+		Replies:    []model.CommentJson{},
+	}
+
+	if !commentVisible(comment, token, post) {
+		hideCommentJson(&result)
+		return result
+	}
+
+	if token != nil {
+		result.CanEdit = canEditComment(token.UserId, comment)
+		result.CanDelete = canDeleteComment(token, comment, post)
+	}
+	return result
+}
+
+// hideCommentJson оставляет от удаленного или скрытого комментария только место в дереве
+func hideCommentJson(result *model.CommentJson) {
+	result.Deleted = true
+	result.AuthorID = 0
+	result.AuthorName = ""
+	result.Body = ""
+	result.BodyHTML = ""
+	result.Pending = false
+	result.CanEdit = false
+	result.CanDelete = false
+}
+
+// getCommentPost возвращает комментарий и пост, к которому он относится
+func getCommentPost(commentID uint) (*model.Comment, *model.Post, error) {
+	var comment model.Comment
+	if err := db.App.First(&comment, commentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("Комментарий не найден")
+		}
+		return nil, nil, err
+	}
+
+	var post model.Post
+	if err := db.App.First(&post, comment.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("Пост не найден")
+		}
+		return nil, nil, err
+	}
+	return &comment, &post, nil
+}
+
+// notifyNewComment уведомляет автора поста о новом опубликованном комментарии
+func notifyNewComment(tx *gorm.DB, comment *model.Comment, postAuthorID uint) error {
+	return notify(tx, model.Notification{
+		UserID:    postAuthorID,
+		ActorID:   comment.AuthorID,
+		Type:      model.NotificationComment,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+}
+
+// NewComment добавляет комментарий к посту или ответ на комментарий и уведомляет автора поста.
+// Об ожидающем одобрения или задержанном фильтром комментарии автор поста узнает после его публикации.
+func NewComment(jwtToken string, req model.NewCommentRequest) (*model.NewCommentResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	if err := db.App.First(&user, token.UserId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пользователь не найден")
+		}
+		return nil, err
+	}
+
+	var post model.Post
+	if err := db.App.First(&post, req.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пост не найден")
+		}
+		return nil, err
+	}
+	if err := checkPostVisible(token, &post); err != nil {
+		return nil, err
+	}
+
+	moderator := canModerateComments(token, &post)
+	if err := checkCanComment(token, &post, moderator); err != nil {
+		return nil, err
+	}
+
+	comment := model.Comment{
+		PostID:   post.ID,
+		AuthorID: token.UserId,
+		Status:   model.CommentApproved,
+	}
+	if post.CommentMode == model.CommentsApproval && !moderator {
+		comment.Status = model.CommentPending
+	}
+
+	if req.ParentID != 0 {
+		var parent model.Comment
+		err := db.App.Where("post_id = ? AND status = ?", post.ID, model.CommentApproved).First(&parent, req.ParentID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Комментарий, на который вы отвечаете, не найден")
+			}
+			return nil, err
+		}
+		comment.ParentID = parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == 0 {
+			comment.RootID = parent.ID
+		}
+
+		if !moderator {
+			if err := checkNotBlocked(token.UserId, parent.AuthorID); err != nil {
+				return nil, err
+			}
+
+			locked, err := threadLocked(comment.RootID)
+			if err != nil {
+				return nil, err
+			}
+			if locked {
+				return nil, fmt.Errorf("Ветка комментариев закрыта")
+			}
+		}
+	}
+
+	comment.Body, comment.BodyHTML, err = renderComment(req.Body, token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Комментарий, не прошедший фильтр, публикуется после проверки модератором, автор поста его одобрить не может
+	fingerprint, reasons, err := screenContent(db.App.DB, filter.TableComments, 0, &user, comment.Body)
+	if err != nil {
+		return nil, err
+	}
+	comment.Fingerprint = fingerprint
+	if len(reasons) > 0 {
+		comment.Status = model.CommentHeld
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return fmt.Errorf("Ошибка при сохранении комментария: %v", err)
+		}
+
+		// Ожидающий одобрения комментарий учитывается, а упомянутые в нем пользователи уведомляются при одобрении
+		if err := saveMentions(tx, post.ID, comment.ID, comment.Body, comment.AuthorID); err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return holdContent(tx, model.ReportComment, comment.ID, comment.AuthorID, post.ID, reasons)
+		}
+		if comment.Status != model.CommentApproved {
+			return nil
+		}
+
+		// Автор поста узнает о комментарии, когда тот опубликован, в том числе после одобрения
+		if err := changeCommentsCount(tx, post.ID, 1); err != nil {
+			return err
+		}
+		if err := notifyMentions(tx, post.ID, comment.ID, comment.AuthorID); err != nil {
+			return err
+		}
+		return notifyNewComment(tx, &comment, post.AuthorID)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при создании комментария: ", err)
+		return nil, err
+	}
+
+	message := "Комментарий добавлен"
+	switch comment.Status {
+	case model.CommentPending:
+		message = "Комментарий будет опубликован после одобрения"
+	case model.CommentHeld:
+		message = "Комментарий будет опубликован после проверки модератором"
+	}
+
+	return &model.NewCommentResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+		Comment: commentToJson(&comment, user.Name, token, &post),
+	}, nil
+}
+
+// UpdateComment изменяет текст комментария. Автор может изменить комментарий только в течение окна редактирования.
+func UpdateComment(jwtToken string, req model.UpdateCommentRequest) (*model.UpdateCommentResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, post, err := getCommentPost(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != token.UserId {
+		return nil, fmt.Errorf("Можно изменять только свои комментарии")
+	}
+	if !canEditComment(token.UserId, comment) {
+		return nil, fmt.Errorf("Время на изменение комментария истекло")
+	}
+
+	body, bodyHTML, err := renderComment(req.Body, comment.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var author model.User
+	if err := db.App.First(&author, comment.AuthorID).Error; err != nil {
+		return nil, err
+	}
+
+	// Опубликованный или ожидающий одобрения комментарий, не прошедший фильтр после изменения,
+	// задерживается до проверки модератором
+	fingerprint, reasons, err := screenContent(db.App.DB, filter.TableComments, comment.ID, &author, body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = withNotifications(func(tx *gorm.DB) error {
+		err := tx.Model(comment).Updates(map[string]interface{}{
+			"body":        body,
+			"body_html":   bodyHTML,
+			"edited_at":   now,
+			"fingerprint": fingerprint,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := saveMentions(tx, post.ID, comment.ID, body, comment.AuthorID); err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			if comment.Status == model.CommentApproved || comment.Status == model.CommentPending {
+				if comment.Status == model.CommentApproved {
+					if err := changeCommentsCount(tx, post.ID, -1); err != nil {
+						return err
+					}
+				}
+				comment.Status = model.CommentHeld
+				if err := tx.Model(comment).UpdateColumn("status", comment.Status).Error; err != nil {
+					return err
+				}
+			}
+			return holdContent(tx, model.ReportComment, comment.ID, comment.AuthorID, post.ID, reasons)
+		}
+		if comment.Status != model.CommentApproved {
+			return nil
+		}
+		return notifyMentions(tx, post.ID, comment.ID, comment.AuthorID)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при изменении комментария: ", err)
+		return nil, fmt.Errorf("Ошибка при сохранении комментария: %v", err)
+	}
+	comment.Body, comment.BodyHTML, comment.EditedAt = body, bodyHTML, &now
+
+	message := "Комментарий изменен"
+	if len(reasons) > 0 {
+		message = "Комментарий изменен и будет опубликован после проверки модератором"
+	}
+
+	return &model.UpdateCommentResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+		Comment: commentToJson(comment, author.Name, token, post),
+	}, nil
+}
+
+// deleteComment удаляет комментарий, опубликованный комментарий перестает учитываться в количестве комментариев поста
+func deleteComment(tx *gorm.DB, comment *model.Comment) error {
+	if err := tx.Delete(comment).Error; err != nil {
+		return fmt.Errorf("Ошибка при удалении комментария: %v", err)
+	}
+
+	if comment.Status != model.CommentApproved {
+		return nil
+	}
+	return changeCommentsCount(tx, comment.PostID, -1)
+}
+
+// DeleteComment удаляет комментарий. Ответы на него остаются в дереве.
+func DeleteComment(jwtToken string, req model.DeleteCommentRequest) (*model.DeleteCommentResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, post, err := getCommentPost(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canDeleteComment(token, comment, post) {
+		return nil, fmt.Errorf("У вас нет доступа к этому комментарию")
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		return deleteComment(tx, comment)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при удалении комментария: ", err)
+		return nil, err
+	}
+
+	return &model.DeleteCommentResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Комментарий удален",
+		},
+	}, nil
+}
+
+// GetComments возвращает страницу веток комментариев поста. Ветка - комментарий верхнего уровня со всеми ответами.
+// Удаленные и скрытые комментарии, а также комментарии авторов, которых пользователь не видит из-за блокировок
+// и скрытия, остаются в дереве без текста, если на них есть ответы. Токен необязателен.
+func GetComments(jwtToken string, req model.GetCommentsRequest) (*model.GetCommentsResponse, error) {
+	var token *model.Token
+	var viewerID uint
+	if jwtToken != "" {
+		token, _ = ParseJWTToken(jwtToken)
+	}
+	if token != nil {
+		viewerID = token.UserId
+	}
+
+	var post model.Post
+	if err := db.App.First(&post, req.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пост не найден")
+		}
+		return nil, err
+	}
+	if post.Status != model.PostPublished && !canModerateComments(token, &post) {
+		return nil, fmt.Errorf("Пост не найден")
+	}
+
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	// Скрытый комментарий верхнего уровня показывается, только если в его ветке есть видимые ответы
+	visibleRoot, rootArgs := visibleCommentsSQL("comments", token, &post)
+	visibleReply, replyArgs := visibleCommentsSQL("c", token, &post)
+	if viewerID != 0 {
+		visibleRoot = "(" + visibleRoot + ") AND " + visibleAuthorSQL("comments.author_id")
+		rootArgs = append(rootArgs, viewerID, viewerID, viewerID)
+		visibleReply = "(" + visibleReply + ") AND " + visibleAuthorSQL("c.author_id")
+		replyArgs = append(replyArgs, viewerID, viewerID, viewerID)
+	}
+	hidden, err := hiddenAuthors(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := db.App.Unscoped().Model(&model.Comment{}).
+		Where("post_id = ? AND parent_id = 0", post.ID).
+		Where("("+visibleRoot+") OR EXISTS (SELECT 1 FROM comments AS c WHERE c.root_id = comments.id AND "+visibleReply+")",
+			append(rootArgs, replyArgs...)...)
+
+	var total int64
+	if err := roots.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []model.Comment
+	err = roots.Order("created_at, id").Offset(offset).Limit(limit).Find(&comments).Error
+	if err != nil {
+		log.App.Error("Ошибка при получении комментариев: ", err)
+		return nil, err
+	}
+
+	rootIDs := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		rootIDs = append(rootIDs, comment.ID)
+	}
+
+	var replies []model.Comment
+	err = db.App.Unscoped().Where("root_id IN ?", rootIDs).Order("created_at, id").Find(&replies).Error
+	if err != nil {
+		log.App.Error("Ошибка при получении ответов на комментарии: ", err)
+		return nil, err
+	}
+
+	authorIDs := make([]uint, 0, len(comments)+len(replies))
+	children := make(map[uint][]model.Comment)
+	for _, comment := range comments {
+		authorIDs = append(authorIDs, comment.AuthorID)
+	}
+	for _, reply := range replies {
+		authorIDs = append(authorIDs, reply.AuthorID)
+		children[reply.ParentID] = append(children[reply.ParentID], reply)
+	}
+
+	var authors []model.User
+	if err := db.App.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	authorNames := make(map[uint]string, len(authors))
+	for _, author := range authors {
+		authorNames[author.ID] = author.Name
+	}
+
+	// build собирает ветку комментария. Скрытые комментарии без ответов отбрасываются.
+	var build func(comment *model.Comment) (model.CommentJson, bool)
+	build = func(comment *model.Comment) (model.CommentJson, bool) {
+		result := commentToJson(comment, authorNames[comment.AuthorID], token, &post)
+		if hidden[comment.AuthorID] {
+			hideCommentJson(&result)
+		}
+		for i := range children[comment.ID] {
+			if reply, ok := build(&children[comment.ID][i]); ok {
+				result.Replies = append(result.Replies, reply)
+			}
+		}
+		return result, !result.Deleted || len(result.Replies) > 0
+	}
+
+	result := make([]model.CommentJson, 0, len(comments))
+	for i := range comments {
+		if comment, ok := build(&comments[i]); ok {
+			result = append(result, comment)
+		}
+	}
+
+	return &model.GetCommentsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Комментарии получены",
+		},
+		Comments: result,
+		Page:     page,
+		Total:    total,
+	}, nil
+}
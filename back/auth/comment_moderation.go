@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// changeCommentsCount изменяет количество опубликованных комментариев поста на delta
+func changeCommentsCount(tx *gorm.DB, postID uint, delta int) error {
+	err := tx.Model(&model.Post{}).Where("id = ?", postID).
+		UpdateColumn("comments_count", gorm.Expr("comments_count + ?", delta)).Error
+	if err != nil {
+		return fmt.Errorf("Ошибка при обновлении количества комментариев: %v", err)
+	}
+	return nil
+}
+
+// checkCanComment проверяет, может ли владелец токена комментировать пост. Модераторы поста
+// могут комментировать закрытый пост, запрет комментировать посты автора и блокировка на них не действуют.
+func checkCanComment(token *model.Token, post *model.Post, moderator bool) error {
+	if moderator {
+		return nil
+	}
+	if post.CommentMode == model.CommentsClosed {
+		return fmt.Errorf("Комментарии к посту закрыты")
+	}
+	if err := checkNotBlocked(token.UserId, post.AuthorID); err != nil {
+		return err
+	}
+
+	var banned int64
+	err := db.App.Model(&model.CommentBan{}).Where("author_id = ? AND user_id = ?", post.AuthorID, token.UserId).Count(&banned).Error
+	if err != nil {
+		return err
+	}
+	if banned > 0 {
+		return fmt.Errorf("Автор запретил вам комментировать его посты")
+	}
+	return nil
+}
+
+// threadLocked проверяет, закрыта ли ветка комментариев с комментарием верхнего уровня rootID
+func threadLocked(rootID uint) (bool, error) {
+	var root model.Comment
+	if err := db.App.Unscoped().Select("locked").First(&root, rootID).Error; err != nil {
+		return false, err
+	}
+	return root.Locked, nil
+}
+
+// SetCommentMode изменяет режим комментариев поста. Доступно автору поста и администратору.
+func SetCommentMode(jwtToken string, req model.SetCommentModeRequest) (*model.SetCommentModeResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := getEditablePost(token, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Mode {
+	case model.CommentsOpen, model.CommentsClosed, model.CommentsApproval:
+	default:
+		return nil, fmt.Errorf("Неизвестный режим комментариев: %s", req.Mode)
+	}
+
+	if err := db.App.Model(post).UpdateColumn("comment_mode", req.Mode).Error; err != nil {
+		log.App.Error("Ошибка при изменении режима комментариев: ", err)
+		return nil, err
+	}
+
+	return &model.SetCommentModeResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Режим комментариев изменен",
+		},
+	}, nil
+}
+
+// GetCommentQueue возвращает комментарии, ожидающие одобрения. Автор видит комментарии к своим постам,
+// администратор и модератор - ко всем постам.
+func GetCommentQueue(jwtToken string, req model.GetCommentQueueRequest) (*model.GetCommentQueueResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Model(&model.Comment{}).
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.status = ?", model.CommentPending)
+	if token.Role != model.AdminRole && token.Role != model.ModeratorRole {
+		query = query.Where("posts.author_id = ?", token.UserId)
+	}
+	if req.PostID != 0 {
+		query = query.Where("comments.post_id = ?", req.PostID)
+	}
+
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []model.Comment
+	err = query.Order("comments.created_at, comments.id").Offset(offset).Limit(limit).Find(&comments).Error
+	if err != nil {
+		log.App.Error("Ошибка при получении очереди комментариев: ", err)
+		return nil, err
+	}
+
+	postIDs := make([]uint, 0, len(comments))
+	authorIDs := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		postIDs = append(postIDs, comment.PostID)
+		authorIDs = append(authorIDs, comment.AuthorID)
+	}
+
+	var posts []model.Post
+	if err := db.App.Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	postsByID := make(map[uint]*model.Post, len(posts))
+	for i := range posts {
+		postsByID[posts[i].ID] = &posts[i]
+	}
+
+	var authors []model.User
+	if err := db.App.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	authorNames := make(map[uint]string, len(authors))
+	for _, author := range authors {
+		authorNames[author.ID] = author.Name
+	}
+
+	result := make([]model.PendingCommentJson, 0, len(comments))
+	for i := range comments {
+		post := postsByID[comments[i].PostID]
+		result = append(result, model.PendingCommentJson{
+			PostID:    post.ID,
+			PostTitle: post.Title,
+			Comment:   commentToJson(&comments[i], authorNames[comments[i].AuthorID], token, post),
+		})
+	}
+
+	return &model.GetCommentQueueResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Очередь комментариев получена",
+		},
+		Comments: result,
+		Page:     page,
+		Total:    total,
+	}, nil
+}
+
+// approveComment публикует комментарий в статусе from (ожидающий одобрения или задержанный фильтром)
+// и уведомляет автора поста и упомянутых в комментарии пользователей
+func approveComment(tx *gorm.DB, comment *model.Comment, from string) error {
+	// Условие на статус не дает одобрить комментарий дважды при параллельных запросах
+	res := tx.Model(&model.Comment{}).Where("id = ? AND status = ?", comment.ID, from).
+		UpdateColumn("status", model.CommentApproved)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+	if err := changeCommentsCount(tx, comment.PostID, 1); err != nil {
+		return err
+	}
+	if err := notifyMentions(tx, comment.PostID, comment.ID, comment.AuthorID); err != nil {
+		return err
+	}
+
+	var post model.Post
+	if err := tx.Unscoped().Select("id, author_id").First(&post, comment.PostID).Error; err != nil {
+		return err
+	}
+	return notifyNewComment(tx, comment, post.AuthorID)
+}
+
+// ModerateComment одобряет или отклоняет комментарий, ожидающий одобрения.
+// Задержанные фильтром комментарии рассматриваются только через жалобы, см. ResolveReport.
+func ModerateComment(jwtToken string, req model.ModerateCommentRequest) (*model.ModerateCommentResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, post, err := getCommentPost(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerateComments(token, post) {
+		return nil, fmt.Errorf("У вас нет доступа к этому комментарию")
+	}
+	if comment.Status == model.CommentHeld {
+		return nil, fmt.Errorf("Комментарий задержан фильтром и будет рассмотрен модератором")
+	}
+	if comment.Status != model.CommentPending {
+		return nil, fmt.Errorf("Комментарий не ожидает одобрения")
+	}
+
+	message := "Комментарий отклонен"
+	if req.Approve {
+		message = "Комментарий одобрен"
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		if req.Approve {
+			return approveComment(tx, comment, model.CommentPending)
+		}
+		return tx.Model(&model.Comment{}).Where("id = ? AND status = ?", comment.ID, model.CommentPending).
+			UpdateColumn("status", model.CommentRejected).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при модерации комментария: ", err)
+		return nil, err
+	}
+
+	return &model.ModerateCommentResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+	}, nil
+}
+
+// LockCommentThread закрывает или открывает для ответов ветку, в которую входит комментарий
+func LockCommentThread(jwtToken string, req model.LockCommentThreadRequest) (*model.LockCommentThreadResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, post, err := getCommentPost(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerateComments(token, post) {
+		return nil, fmt.Errorf("У вас нет доступа к этому комментарию")
+	}
+
+	rootID := comment.RootID
+	if rootID == 0 {
+		rootID = comment.ID
+	}
+	if err := db.App.Unscoped().Model(&model.Comment{}).Where("id = ?", rootID).UpdateColumn("locked", req.Locked).Error; err != nil {
+		log.App.Error("Ошибка при закрытии ветки комментариев: ", err)
+		return nil, err
+	}
+
+	message := "Ветка комментариев открыта"
+	if req.Locked {
+		message = "Ветка комментариев закрыта"
+	}
+
+	return &model.LockCommentThreadResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+	}, nil
+}
+
+// BanCommenter запрещает пользователю комментировать посты владельца токена
+func BanCommenter(jwtToken string, req model.CommentBanRequest) (*model.CommentBanResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == token.UserId {
+		return nil, fmt.Errorf("Нельзя запретить комментарии самому себе")
+	}
+
+	var user model.User
+	if err := db.App.First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пользователь не найден")
+		}
+		return nil, err
+	}
+
+	ban := model.CommentBan{AuthorID: token.UserId, UserID: user.ID}
+	if err := db.App.Clauses(clause.OnConflict{DoNothing: true}).Create(&ban).Error; err != nil {
+		log.App.Error("Ошибка при запрете комментариев: ", err)
+		return nil, err
+	}
+
+	return &model.CommentBanResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пользователю запрещено комментировать ваши посты",
+		},
+	}, nil
+}
+
+// UnbanCommenter снимает запрет комментировать посты владельца токена
+func UnbanCommenter(jwtToken string, req model.CommentBanRequest) (*model.CommentBanResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("author_id = ? AND user_id = ?", token.UserId, req.UserID).Delete(&model.CommentBan{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при снятии запрета комментариев: ", err)
+		return nil, err
+	}
+
+	return &model.CommentBanResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Запрет комментариев снят",
+		},
+	}, nil
+}
+
+// GetCommentBans возвращает пользователей, которым запрещено комментировать посты владельца токена
+func GetCommentBans(jwtToken string) (*model.GetCommentBansResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var bans []model.CommentBan
+	if err := db.App.Where("author_id = ?", token.UserId).Order("created_at DESC").Find(&bans).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint, 0, len(bans))
+	for _, ban := range bans {
+		userIDs = append(userIDs, ban.UserID)
+	}
+	var users []model.User
+	if err := db.App.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+
+	result := make([]model.BannedCommenterJson, 0, len(bans))
+	for _, ban := range bans {
+		result = append(result, model.BannedCommenterJson{
+			UserID: ban.UserID,
+			Name:   names[ban.UserID],
+			Date:   ban.CreatedAt.Format("02.01.2006"),
+		})
+	}
+
+	return &model.GetCommentBansResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Список получен",
+		},
+		Users: result,
+	}, nil
+}
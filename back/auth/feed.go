@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"app/db"
+	"app/model"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20  // Количество постов на странице по умолчанию
+	maxPageSize     = 100 // Максимальное количество постов на странице
+)
+
+// pagination возвращает номер страницы, размер страницы и смещение с учетом значений по умолчанию
+func pagination(page, limit int) (int, int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// feedOrder возвращает порядок сортировки постов для ленты
+func feedOrder(sort string) (string, error) {
+	switch sort {
+	case "", model.SortByDate:
+		return "posts.created_at DESC, posts.id DESC", nil
+	case model.SortByLikes:
+		return "posts.likes_count DESC, posts.created_at DESC, posts.id DESC", nil
+	default:
+		return "", fmt.Errorf("Неизвестная сортировка: %s", sort)
+	}
+}
+
+// optionalUserID возвращает ID пользователя из токена или 0, если токена нет или он недействителен
+func optionalUserID(jwtToken string) uint {
+	if jwtToken == "" {
+		return 0
+	}
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return 0
+	}
+	return token.UserId
+}
+
+// visiblePosts оставляет в запросе постов только опубликованные посты и исключает посты авторов,
+// которых пользователь viewerID не видит: заблокированных им, заблокировавших его и скрытых им.
+func visiblePosts(query *gorm.DB, viewerID uint) *gorm.DB {
+	query = query.Where("posts.status = ?", model.PostPublished)
+	if viewerID == 0 {
+		return query
+	}
+	return query.Where(visibleAuthorSQL("posts.author_id"), viewerID, viewerID, viewerID)
+}
+
+// visibleAuthorSQL возвращает SQL-условие для столбца column с ID автора: автор не заблокирован пользователем,
+// не заблокировал его и не скрыт им. Условию нужен ID пользователя три раза.
+func visibleAuthorSQL(column string) string {
+	return column + ` NOT IN (SELECT blocked_id FROM blocks WHERE blocker_id = ?)
+		AND ` + column + ` NOT IN (SELECT blocker_id FROM blocks WHERE blocked_id = ?)
+		AND ` + column + ` NOT IN (SELECT muted_id FROM mutes WHERE muter_id = ?)`
+}
+
+// hiddenAuthors возвращает авторов, которых пользователь viewerID не видит: заблокированных им,
+// заблокировавших его и скрытых им
+func hiddenAuthors(viewerID uint) (map[uint]bool, error) {
+	hidden := make(map[uint]bool)
+	if viewerID == 0 {
+		return hidden, nil
+	}
+
+	var ids []uint
+	err := db.App.Raw(`SELECT blocked_id FROM blocks WHERE blocker_id = ?
+		UNION SELECT blocker_id FROM blocks WHERE blocked_id = ?
+		UNION SELECT muted_id FROM mutes WHERE muter_id = ?`, viewerID, viewerID, viewerID).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// checkPostVisible проверяет, что пост доступен пользователю из токена. Скрытый модерацией пост видят только автор,
+// администраторы и модераторы. token может быть nil для анонимного пользователя.
+func checkPostVisible(token *model.Token, post *model.Post) error {
+	var viewerID uint
+	if token != nil {
+		viewerID = token.UserId
+	}
+	if post.Status != model.PostPublished && (token == nil || post.AuthorID != token.UserId && !canModerate(token)) {
+		return fmt.Errorf("Пост не найден")
+	}
+	// Пользователи, заблокировавшие друг друга, не видят посты друг друга
+	return checkNotBlocked(viewerID, post.AuthorID)
+}
+
+// feedPage загружает страницу постов из запроса query вместе с общим количеством постов
+func feedPage(query *gorm.DB, sort string, page, limit int) ([]model.Post, int64, int, error) {
+	order, err := feedOrder(sort)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	page, limit, offset := pagination(page, limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	var posts []model.Post
+	err = query.Preload("Tags").Order(order).Offset(offset).Limit(limit).Find(&posts).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return posts, total, page, nil
+}
+
+// postsToFeed преобразует посты в формат ленты. viewerID - пользователь, для которого
+// заполняются его реакции и закладки, 0 - анонимный пользователь.
+func postsToFeed(posts []model.Post, viewerID uint) ([]model.PostForFeed, error) {
+	postIDs := make([]uint, 0, len(posts))
+	authorIDs := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+		authorIDs = append(authorIDs, post.AuthorID)
+	}
+
+	var authors []model.User
+	if err := db.App.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при получении авторов: %v", err)
+	}
+	authorNames := make(map[uint]string, len(authors))
+	for _, author := range authors {
+		authorNames[author.ID] = author.Name
+	}
+
+	reactions, myReactions, err := postReactions(postIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	bookmarked, err := bookmarkedPosts(postIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	covers, err := postCovers(posts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.PostForFeed, 0, len(posts))
+	for i := range posts {
+		postDB := &posts[i]
+		ensureRendered(db.App.DB, postDB)
+
+		result = append(result, model.PostForFeed{
+			ID:          postDB.ID,
+			Slug:        postDB.Slug,
+			URL:         postURL(postDB.Slug),
+			Title:       postDB.Title,
+			SubTitle:    postDB.SubTitle,
+			Excerpt:     postDB.Excerpt,
+			WordCount:   postDB.WordCount,
+			ReadingTime: postDB.ReadingTime,
+			Tags:        tagNames(postDB.Tags),
+			Status:      postDB.Status,
+			AuthorName:  authorNames[postDB.AuthorID],
+			Likes:       postDB.LikesCount,
+			Comments:    postDB.CommentsCount,
+			AuthorId:    postDB.AuthorID,
+			Reactions:   reactions[postDB.ID],
+			MyReactions: myReactions[postDB.ID],
+			Bookmarked:  bookmarked[postDB.ID],
+			Cover:       covers[postDB.CoverMediaID],
+			Date:        postDB.CreatedAt.Format("02.01.2006"),
+		})
+	}
+	return result, nil
+}
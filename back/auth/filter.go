@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"app/db"
+	"app/filter"
+	"app/log"
+	"app/model"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// screenContent проверяет фильтром текст поста или комментария, который сохраняет пользователь user.
+// Возвращает отпечаток текста и причины задержки. Тексты администраторов и модераторов не задерживаются.
+func screenContent(tx *gorm.DB, table string, id uint, user *model.User, text string) (string, []string, error) {
+	content := filter.NewContent(table, id, user, text)
+	if user.Role == model.AdminRole || user.Role == model.ModeratorRole {
+		return content.Fingerprint(), nil, nil
+	}
+
+	reasons, err := filter.App.Run(tx, content)
+	if err != nil {
+		log.App.Error("Ошибка при проверке содержимого: ", err)
+		return "", nil, err
+	}
+	return content.Fingerprint(), reasons, nil
+}
+
+// postText возвращает текст поста для фильтра
+func postText(post *model.Post) string {
+	return post.Title + "\n" + post.SubTitle + "\n" + post.Content
+}
+
+// holdContent отправляет задержанный фильтром пост или комментарий в очередь жалоб.
+// Содержимое будет опубликовано, если модератор отклонит жалобу.
+func holdContent(tx *gorm.DB, targetType string, targetID, authorID, postID uint, reasons []string) error {
+	report := &model.Report{
+		TargetType:     targetType,
+		TargetID:       targetID,
+		TargetAuthorID: authorID,
+		PostID:         postID,
+		Status:         model.ReportOpen,
+		Held:           true,
+	}
+	return fileReport(tx, report, 0, model.ReasonFilter, strings.Join(reasons, "; "))
+}
+
+// filterRulesToJson преобразует правила фильтра в формат ответа
+func filterRulesToJson(rules model.FilterRules) model.FilterRulesJson {
+	return model.FilterRulesJson{
+		MaxLinks:           rules.MaxLinks,
+		MaxLinkDensity:     rules.MaxLinkDensity,
+		BannedWords:        rules.BannedWords,
+		BannedPatterns:     rules.BannedPatterns,
+		DuplicateWindow:    rules.DuplicateWindow,
+		DuplicateMinLength: rules.DuplicateMinLength,
+		PostsPerHour:       rules.PostsPerHour,
+		CommentsPerHour:    rules.CommentsPerHour,
+		ProbationDays:      rules.ProbationDays,
+		ProbationMaxLinks:  rules.ProbationMaxLinks,
+	}
+}
+
+// GetFilterRules возвращает действующие правила фильтра. Доступно только администратору.
+func GetFilterRules(jwtToken string) (*model.GetFilterRulesResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Role != model.AdminRole {
+		return nil, fmt.Errorf("Правила фильтра доступны только администратору")
+	}
+
+	return &model.GetFilterRulesResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Правила фильтра получены",
+		},
+		Rules: filterRulesToJson(filter.App.Rules()),
+	}, nil
+}
+
+// UpdateFilterRules сохраняет правила фильтра и сразу применяет их. Доступно только администратору.
+func UpdateFilterRules(jwtToken string, req model.UpdateFilterRulesRequest) (*model.UpdateFilterRulesResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Role != model.AdminRole {
+		return nil, fmt.Errorf("Изменять правила фильтра может только администратор")
+	}
+
+	rules := model.FilterRules{
+		ID:                 1,
+		UpdatedBy:          token.UserId,
+		MaxLinks:           req.Rules.MaxLinks,
+		MaxLinkDensity:     req.Rules.MaxLinkDensity,
+		BannedWords:        trimList(req.Rules.BannedWords),
+		BannedPatterns:     trimList(req.Rules.BannedPatterns),
+		DuplicateWindow:    req.Rules.DuplicateWindow,
+		DuplicateMinLength: req.Rules.DuplicateMinLength,
+		PostsPerHour:       req.Rules.PostsPerHour,
+		CommentsPerHour:    req.Rules.CommentsPerHour,
+		ProbationDays:      req.Rules.ProbationDays,
+		ProbationMaxLinks:  req.Rules.ProbationMaxLinks,
+	}
+	if rules.MaxLinks < 0 || rules.MaxLinkDensity < 0 || rules.DuplicateWindow < 0 || rules.DuplicateMinLength < 0 ||
+		rules.PostsPerHour < 0 || rules.CommentsPerHour < 0 || rules.ProbationDays < 0 || rules.ProbationMaxLinks < 0 {
+		return nil, fmt.Errorf("Числовые правила фильтра не могут быть отрицательными")
+	}
+
+	compiled, err := filter.Compile(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.App.Save(&rules).Error; err != nil {
+		log.App.Error("Ошибка при сохранении правил фильтра: ", err)
+		return nil, err
+	}
+	filter.App.SetRules(compiled)
+
+	return &model.UpdateFilterRulesResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Правила фильтра сохранены",
+		},
+		Rules: filterRulesToJson(rules),
+	}, nil
+}
+
+// trimList убирает пробелы по краям элементов списка и пустые элементы
+func trimList(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// followCounts возвращает количество подписчиков пользователя и авторов, на которых он подписан
+func followCounts(userID uint) (int64, int64, error) {
+	var followers, following int64
+	if err := db.App.Model(&model.Follow{}).Where("followee_id = ?", userID).Count(&followers).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := db.App.Model(&model.Follow{}).Where("follower_id = ?", userID).Count(&following).Error; err != nil {
+		return 0, 0, err
+	}
+	return followers, following, nil
+}
+
+// feedCursor возвращает курсор, указывающий на место поста в ленте
+func feedCursor(post *model.Post) string {
+	return fmt.Sprintf("%d_%d", post.CreatedAt.UnixNano(), post.ID)
+}
+
+// parseFeedCursor разбирает курсор ленты на время создания и ID поста
+func parseFeedCursor(cursor string) (time.Time, uint, error) {
+	createdAt, id, ok := strings.Cut(cursor, "_")
+	if ok {
+		nanos, errTime := strconv.ParseInt(createdAt, 10, 64)
+		postID, errID := strconv.ParseUint(id, 10, 64)
+		if errTime == nil && errID == nil {
+			return time.Unix(0, nanos), uint(postID), nil
+		}
+	}
+	return time.Time{}, 0, fmt.Errorf("Недопустимый курсор ленты")
+}
+
+// FollowUser подписывает владельца токена на автора. Повторная подписка не считается ошибкой.
+func FollowUser(jwtToken string, req model.FollowUserRequest) (*model.FollowUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == token.UserId {
+		return nil, fmt.Errorf("Нельзя подписаться на самого себя")
+	}
+
+	var user model.User
+	if err := db.App.First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пользователь не найден")
+		}
+		return nil, err
+	}
+	if err := checkNotBlocked(token.UserId, user.ID); err != nil {
+		return nil, err
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		follow := model.Follow{FollowerID: token.UserId, FolloweeID: user.ID}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		return notify(tx, model.Notification{
+			UserID:  user.ID,
+			ActorID: token.UserId,
+			Type:    model.NotificationFollow,
+		})
+	})
+	if err != nil {
+		log.App.Error("Ошибка при подписке на автора: ", err)
+		return nil, err
+	}
+
+	followers, _, err := followCounts(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FollowUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Вы подписались на автора",
+		},
+		Followers: followers,
+	}, nil
+}
+
+// UnfollowUser отписывает владельца токена от автора
+func UnfollowUser(jwtToken string, req model.FollowUserRequest) (*model.FollowUserResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("follower_id = ? AND followee_id = ?", token.UserId, req.UserID).Delete(&model.Follow{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при отписке от автора: ", err)
+		return nil, err
+	}
+
+	followers, _, err := followCounts(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FollowUserResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Вы отписались от автора",
+		},
+		Followers: followers,
+	}, nil
+}
+
+// GetUserProfile возвращает профиль пользователя по ID или, если ID не указан, по имени пользователя.
+// Токен необязателен: он нужен, чтобы отметить подписку на пользователя.
+func GetUserProfile(jwtToken string, req model.ProfileRequest) (*model.ProfileResponse, error) {
+	query := db.App.Where("id = ?", req.ID)
+	if req.ID == 0 {
+		query = db.App.Where("username = ?", strings.ToLower(strings.TrimSpace(req.Username)))
+	}
+
+	var user model.User
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пользователь не найден")
+		}
+		return nil, err
+	}
+
+	followers, following, err := followCounts(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var followed, blocked, muted int64
+	if viewerID := optionalUserID(jwtToken); viewerID != 0 {
+		err := db.App.Model(&model.Follow{}).Where("follower_id = ? AND followee_id = ?", viewerID, user.ID).Count(&followed).Error
+		if err != nil {
+			return nil, err
+		}
+		err = db.App.Model(&model.Block{}).Where("blocker_id = ? AND blocked_id = ?", viewerID, user.ID).Count(&blocked).Error
+		if err != nil {
+			return nil, err
+		}
+		err = db.App.Model(&model.Mute{}).Where("muter_id = ? AND muted_id = ?", viewerID, user.ID).Count(&muted).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.ProfileResponse{
+		Status:    true,
+		Name:      user.Name,
+		Username:  user.Username,
+		Followers: followers,
+		Following: following,
+		Followed:  followed > 0,
+		Blocked:   blocked > 0,
+		Muted:     muted > 0,
+	}, nil
+}
+
+// GetFollowingFeed возвращает ленту постов авторов, на которых подписан пользователь, начиная с новых.
+// Посты выбираются одним запросом при чтении, а страницы листаются курсором по (created_at, id):
+// в отличие от смещения, глубокие страницы не замедляются.
+func GetFollowingFeed(jwtToken string, req model.GetFollowingFeedRequest) (*model.GetFollowingFeedResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	_, limit, _ := pagination(1, req.Limit)
+	query := db.App.Preload("Tags").
+		Where("posts.author_id IN (SELECT followee_id FROM follows WHERE follower_id = ?)", token.UserId)
+	query = visiblePosts(query, token.UserId)
+	if req.Cursor != "" {
+		createdAt, id, err := parseFeedCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("posts.created_at < ? OR (posts.created_at = ? AND posts.id < ?)", createdAt, createdAt, id)
+	}
+
+	// Лишний пост показывает, есть ли следующая страница
+	var posts []model.Post
+	if err := query.Order("posts.created_at DESC, posts.id DESC").Limit(limit + 1).Find(&posts).Error; err != nil {
+		log.App.Error("Ошибка при получении ленты подписок на авторов: ", err)
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		nextCursor = feedCursor(&posts[limit-1])
+	}
+
+	feed, err := postsToFeed(posts, token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetFollowingFeedResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Лента подписок получена",
+		},
+		Posts:      feed,
+		NextCursor: nextCursor,
+	}, nil
+}
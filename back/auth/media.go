@@ -0,0 +1,550 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/markdown"
+	"app/media"
+	"app/model"
+	"app/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// megabyte размер мегабайта в байтах
+const megabyte = 1 << 20
+
+// mediaURL возвращает адрес файла в хранилище
+func mediaURL(key string) string {
+	if media.App == nil {
+		return media.URLPrefix + key
+	}
+	return media.App.URL(key)
+}
+
+// mediaName приводит имя файла пользователя к виду, пригодному для хранения и показа
+func mediaName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// mediaVariants возвращает варианты изображений ids от меньшего к большему
+func mediaVariants(tx *gorm.DB, ids []uint) (map[uint][]model.MediaVariant, error) {
+	var variants []model.MediaVariant
+	if err := tx.Where("media_id IN ?", ids).Order("width, id").Find(&variants).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при получении вариантов изображений: %v", err)
+	}
+
+	result := make(map[uint][]model.MediaVariant, len(ids))
+	for _, variant := range variants {
+		result[variant.MediaID] = append(result[variant.MediaID], variant)
+	}
+	return result, nil
+}
+
+// variantsToJson преобразует варианты изображения в формат ответа
+func variantsToJson(variants []model.MediaVariant) []model.MediaVariantJson {
+	result := make([]model.MediaVariantJson, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, model.MediaVariantJson{
+			Name:   variant.Name,
+			Format: variant.Format,
+			URL:    mediaURL(variant.Key),
+			Width:  variant.Width,
+			Height: variant.Height,
+			Size:   variant.Size,
+		})
+	}
+	return result
+}
+
+// postCovers возвращает обработанные обложки постов по ID файла
+func postCovers(posts []model.Post) (map[uint]*model.ImageJson, error) {
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		if post.CoverMediaID != 0 {
+			ids = append(ids, post.CoverMediaID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var files []model.Media
+	if err := db.App.Where("id IN ? AND status = ?", ids, model.MediaReady).Find(&files).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при получении обложек: %v", err)
+	}
+	variants, err := mediaVariants(db.App.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]*model.ImageJson, len(files))
+	for _, file := range files {
+		result[file.ID] = &model.ImageJson{
+			URL:      mediaURL(file.Key),
+			Width:    file.Width,
+			Height:   file.Height,
+			Blurhash: file.Blurhash,
+			Variants: variantsToJson(variants[file.ID]),
+		}
+	}
+	return result, nil
+}
+
+// mediaToJson преобразует файлы в формат ответа. usage - количество постов, ссылающихся на файл.
+func mediaToJson(files []model.Media, usage map[uint]int64, variants map[uint][]model.MediaVariant) []model.MediaJson {
+	result := make([]model.MediaJson, 0, len(files))
+	for _, file := range files {
+		result = append(result, model.MediaJson{
+			ID:          file.ID,
+			Name:        file.Name,
+			URL:         mediaURL(file.Key),
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			Markdown:    markdown.MediaLink(file.ID, strings.TrimSuffix(file.Name, filepath.Ext(file.Name))),
+			UsedIn:      usage[file.ID],
+			Status:      file.Status,
+			Width:       file.Width,
+			Height:      file.Height,
+			Blurhash:    file.Blurhash,
+			Variants:    variantsToJson(variants[file.ID]),
+			CreatedAt:   file.CreatedAt.Format("02.01.2006 15:04"),
+		})
+	}
+	return result
+}
+
+// mediaUsage возвращает количество постов, ссылающихся на каждый из файлов ids
+func mediaUsage(tx *gorm.DB, ids []uint) (map[uint]int64, error) {
+	var rows []struct {
+		MediaID uint
+		Count   int64
+	}
+	err := tx.Model(&model.PostMedia{}).
+		Select("media_id, COUNT(*) AS count").
+		Where("media_id IN ?", ids).
+		Group("media_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		usage[row.MediaID] = row.Count
+	}
+	return usage, nil
+}
+
+// mediaQuotaUsed возвращает размер файлов, загруженных пользователем userID
+func mediaQuotaUsed(tx *gorm.DB, userID uint) (int64, error) {
+	var used int64
+	err := tx.Model(&model.Media{}).Where("owner_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	return used, err
+}
+
+// checkMediaQuota проверяет, что файл размером size помещается в квоту пользователя userID
+func checkMediaQuota(tx *gorm.DB, userID uint, size int64) error {
+	quota := int64(config.File.MediaConfig.UserQuota) * megabyte
+	if quota <= 0 {
+		return nil
+	}
+	used, err := mediaQuotaUsed(tx, userID)
+	if err != nil {
+		return err
+	}
+	if used+size > quota {
+		return fmt.Errorf("Превышена квота файлов: занято %.1f из %d МБ", float64(used)/megabyte, config.File.MediaConfig.UserQuota)
+	}
+	return nil
+}
+
+// UploadMedia сохраняет файл data, загруженный пользователем под именем name. Тип файла определяется
+// по содержимому, а не по имени. Повторная загрузка того же файла возвращает уже загруженный.
+func UploadMedia(jwtToken string, name string, data []byte) (*model.UploadMediaResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if media.App == nil {
+		return nil, fmt.Errorf("Хранилище файлов не настроено")
+	}
+
+	conf := config.File.MediaConfig
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Файл пустой")
+	}
+	if int64(len(data)) > int64(conf.MaxSize)*megabyte {
+		return nil, fmt.Errorf("Файл больше %d МБ", conf.MaxSize)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(conf.AllowedTypes, contentType) || media.Extension(contentType) == "" {
+		return nil, fmt.Errorf("Недопустимый тип файла: %s. Можно загружать %s", contentType, strings.Join(conf.AllowedTypes, ", "))
+	}
+	width, height, err := media.DecodeConfig(data, conf.MaxPixels*1000000)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var existing model.Media
+	err = db.App.Where("owner_id = ? AND hash = ?", token.UserId, hash).Limit(1).Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	if existing.ID != 0 {
+		usage, err := mediaUsage(db.App.DB, []uint{existing.ID})
+		if err != nil {
+			return nil, err
+		}
+		variants, err := mediaVariants(db.App.DB, []uint{existing.ID})
+		if err != nil {
+			return nil, err
+		}
+		return &model.UploadMediaResponse{
+			Response: model.Response{
+				Status:  true,
+				Message: "Файл уже загружен",
+			},
+			Media: mediaToJson([]model.Media{existing}, usage, variants)[0],
+		}, nil
+	}
+
+	// Предварительная проверка квоты, чтобы не загружать в хранилище заведомо лишний файл
+	if err := checkMediaQuota(db.App.DB, token.UserId, int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	random, err := utils.GenerateToken(16)
+	if err != nil {
+		return nil, err
+	}
+	file := model.Media{
+		OwnerID:     token.UserId,
+		Key:         random + media.Extension(contentType),
+		Name:        mediaName(name),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Hash:        hash,
+		Status:      model.MediaProcessing,
+		Width:       width,
+		Height:      height,
+	}
+
+	if err := media.App.Put(file.Key, file.ContentType, data); err != nil {
+		log.App.Error("Ошибка при сохранении файла в хранилище: ", err)
+		return nil, fmt.Errorf("Не удалось сохранить файл")
+	}
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		// Блокировка пользователя не дает одновременным загрузкам пройти проверку квоты по одному и тому же остатку
+		var owner model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&owner, token.UserId).Error; err != nil {
+			return err
+		}
+		if err := checkMediaQuota(tx, token.UserId, file.Size); err != nil {
+			return err
+		}
+		return tx.Create(&file).Error
+	})
+	if err != nil {
+		removeMediaFiles([]string{file.Key})
+		return nil, err
+	}
+
+	// Пока файл не обработан, он не отдается читателям: в нем еще есть метаданные.
+	// Если очередь заполнена, файл подберет периодический поиск необработанных файлов.
+	media.Workers.Enqueue(file.ID)
+
+	return &model.UploadMediaResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Файл загружен и обрабатывается",
+		},
+		Media: mediaToJson([]model.Media{file}, nil, nil)[0],
+	}, nil
+}
+
+// GetMediaLibrary возвращает страницу файлов пользователя начиная с последних и занятую ими квоту
+func GetMediaLibrary(jwtToken string, req model.GetMediaLibraryRequest) (*model.GetMediaLibraryResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Model(&model.Media{}).Where("owner_id = ?", token.UserId)
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var files []model.Media
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&files).Error; err != nil {
+		log.App.Error("Ошибка при получении файлов: ", err)
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+	}
+	usage, err := mediaUsage(db.App.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := mediaVariants(db.App.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := mediaQuotaUsed(db.App.DB, token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetMediaLibraryResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Файлы получены",
+		},
+		Media:      mediaToJson(files, usage, variants),
+		Page:       page,
+		Total:      total,
+		QuotaUsed:  used,
+		QuotaLimit: int64(config.File.MediaConfig.UserQuota) * megabyte,
+	}, nil
+}
+
+// DeleteMedia удаляет файл пользователя. Файл, на который ссылается хотя бы один пост, в том числе
+// находящийся в корзине, удалить нельзя: сначала ссылку нужно убрать из текста.
+func DeleteMedia(jwtToken string, req model.DeleteMediaRequest) (*model.DeleteMediaResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		var file model.Media
+		if err := tx.Where("id = ? AND owner_id = ?", req.ID, token.UserId).First(&file).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("Файл не найден")
+			}
+			return err
+		}
+
+		var used int64
+		if err := tx.Model(&model.PostMedia{}).Where("media_id = ?", file.ID).Count(&used).Error; err != nil {
+			return err
+		}
+		if used > 0 {
+			return fmt.Errorf("Файл используется в постах: %d", used)
+		}
+
+		var err error
+		keys, err = deleteMediaRecords(tx, []model.Media{file})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeMediaFiles(keys)
+
+	return &model.DeleteMediaResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Файл удален",
+		},
+	}, nil
+}
+
+// GetMediaFile открывает загруженный файл или его вариант по имени в хранилище.
+// Необработанные файлы не отдаются: метаданные из них еще не удалены.
+func GetMediaFile(key string) (*model.Media, io.ReadCloser, error) {
+	if media.App == nil {
+		return nil, nil, media.ErrNotFound
+	}
+
+	var file model.Media
+	if err := db.App.Where("key = ?", key).Limit(1).Find(&file).Error; err != nil {
+		return nil, nil, err
+	}
+	if file.ID == 0 {
+		var variant model.MediaVariant
+		if err := db.App.Where("key = ?", key).Limit(1).Find(&variant).Error; err != nil {
+			return nil, nil, err
+		}
+		if variant.ID == 0 {
+			return nil, nil, media.ErrNotFound
+		}
+		file = model.Media{Key: variant.Key, ContentType: media.ContentType(variant.Format), Size: variant.Size, Status: model.MediaReady}
+	}
+	if file.Status != model.MediaReady {
+		return nil, nil, media.ErrNotFound
+	}
+
+	reader, err := media.App.Open(file.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &file, reader, nil
+}
+
+// referencedMedia возвращает файлы автора authorID, на которые ссылается текст, в порядке первой ссылки.
+// Ссылки на чужие файлы не учитываются.
+func referencedMedia(tx *gorm.DB, source string, authorID uint) ([]model.Media, error) {
+	ids := markdown.App.MediaRefs(source)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var files []model.Media
+	if err := tx.Where("id IN ? AND owner_id = ?", ids, authorID).Find(&files).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при поиске загруженных файлов: %v", err)
+	}
+	slices.SortFunc(files, func(a, b model.Media) int {
+		return slices.Index(ids, a.ID) - slices.Index(ids, b.ID)
+	})
+	return files, nil
+}
+
+// renderPostContent рендерит текст поста автора authorID, заменяя упоминания ссылками на профили,
+// а ссылки media:ID - адресами загруженных файлов
+func renderPostContent(tx *gorm.DB, source string, authorID uint) (string, error) {
+	profiles, err := mentionProfiles(tx, source, authorID)
+	if err != nil {
+		return "", err
+	}
+	files, err := referencedMedia(tx, source, authorID)
+	if err != nil {
+		return "", err
+	}
+
+	urls := make(map[uint]string, len(files))
+	for _, file := range files {
+		urls[file.ID] = mediaURL(file.Key)
+	}
+	return markdown.App.RenderLinks(source, markdown.Links{Profiles: profiles, Media: urls})
+}
+
+// savePostMedia сохраняет ссылки поста на загруженные файлы и выбирает обложку - первое изображение из текста.
+// Ссылки, удаленные из текста, удаляются, но сами файлы остаются в библиотеке автора.
+func savePostMedia(tx *gorm.DB, postID uint, source string, authorID uint) error {
+	files, err := referencedMedia(tx, source, authorID)
+	if err != nil {
+		return err
+	}
+
+	var coverID uint
+	if len(files) > 0 {
+		coverID = files[0].ID
+	}
+	if err := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumn("cover_media_id", coverID).Error; err != nil {
+		return fmt.Errorf("Ошибка при сохранении обложки поста: %v", err)
+	}
+
+	mediaIDs := make([]uint, 0, len(files))
+	for _, file := range files {
+		mediaIDs = append(mediaIDs, file.ID)
+	}
+
+	stale := tx.Where("post_id = ?", postID)
+	if len(mediaIDs) > 0 {
+		stale = stale.Where("media_id NOT IN ?", mediaIDs)
+	}
+	if err := stale.Delete(&model.PostMedia{}).Error; err != nil {
+		return fmt.Errorf("Ошибка при сохранении ссылок на файлы: %v", err)
+	}
+
+	for _, mediaID := range mediaIDs {
+		link := model.PostMedia{PostID: postID, MediaID: mediaID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return fmt.Errorf("Ошибка при сохранении ссылок на файлы: %v", err)
+		}
+	}
+	return nil
+}
+
+// releasePostMedia удаляет ссылки окончательно удаляемых постов postIDs на файлы и сами файлы,
+// на которые больше не ссылается ни один пост. Возвращает имена файлов, которые нужно удалить из хранилища.
+func releasePostMedia(tx *gorm.DB, postIDs []uint) ([]string, error) {
+	var mediaIDs []uint
+	if err := tx.Model(&model.PostMedia{}).Where("post_id IN ?", postIDs).Distinct().Pluck("media_id", &mediaIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id IN ?", postIDs).Delete(&model.PostMedia{}).Error; err != nil {
+		return nil, err
+	}
+	if len(mediaIDs) == 0 {
+		return nil, nil
+	}
+
+	var orphans []model.Media
+	err := tx.Where("id IN ?", mediaIDs).
+		Where("id NOT IN (SELECT media_id FROM post_media)").
+		Find(&orphans).Error
+	if err != nil || len(orphans) == 0 {
+		return nil, err
+	}
+	return deleteMediaRecords(tx, orphans)
+}
+
+// deleteMediaRecords удаляет записи о файлах и их вариантах. Возвращает имена файлов, которые нужно удалить из хранилища.
+func deleteMediaRecords(tx *gorm.DB, files []model.Media) ([]string, error) {
+	ids := make([]uint, 0, len(files))
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+		keys = append(keys, file.Key)
+	}
+
+	var variantKeys []string
+	if err := tx.Model(&model.MediaVariant{}).Where("media_id IN ?", ids).Pluck("key", &variantKeys).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("media_id IN ?", ids).Delete(&model.MediaVariant{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&model.Media{}).Error; err != nil {
+		return nil, err
+	}
+	return append(keys, variantKeys...), nil
+}
+
+// removeMediaFiles удаляет файлы из хранилища. Ошибка только записывается в журнал: запись о файле
+// уже удалена, и оставшийся в хранилище файл никому не виден.
+func removeMediaFiles(keys []string) {
+	if media.App == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := media.App.Delete(key); err != nil {
+			log.App.Error("Ошибка при удалении файла ", key, " из хранилища: ", err)
+		}
+	}
+}
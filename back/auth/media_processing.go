@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/media"
+	"app/model"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// variantKey возвращает имя варианта name файла key в хранилище, например abc-thumbnail.webp
+func variantKey(key, name, format string) string {
+	return strings.TrimSuffix(key, filepath.Ext(key)) + "-" + name + media.Extension(media.ContentType(format))
+}
+
+// ProcessMedia обрабатывает загруженное изображение: удаляет метаданные, создает уменьшенные варианты
+// и заглушку blurhash. Вызывается фоновыми обработчиками.
+func ProcessMedia(id uint) error {
+	if media.App == nil {
+		return nil
+	}
+
+	var file model.Media
+	if err := db.App.Where("id = ?", id).Limit(1).Find(&file).Error; err != nil {
+		return err
+	}
+	if file.ID == 0 || file.Status != model.MediaProcessing {
+		return nil
+	}
+
+	reader, err := media.App.Open(file.Key)
+	if err != nil {
+		return failMedia(file.ID, err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return failMedia(file.ID, err)
+	}
+
+	processed, err := media.Process(file.ContentType, data, media.ProcessOptions())
+	if err != nil {
+		return failMedia(file.ID, err)
+	}
+
+	variants := make([]model.MediaVariant, 0, len(processed.Variants))
+	keys := make([]string, 0, len(processed.Variants))
+	for _, variant := range processed.Variants {
+		key := variantKey(file.Key, variant.Name, variant.Format)
+		if err := media.App.Put(key, media.ContentType(variant.Format), variant.Data); err != nil {
+			removeMediaFiles(keys)
+			return failMedia(file.ID, err)
+		}
+		keys = append(keys, key)
+		variants = append(variants, model.MediaVariant{
+			MediaID: file.ID,
+			Name:    variant.Name,
+			Format:  variant.Format,
+			Key:     key,
+			Width:   variant.Width,
+			Height:  variant.Height,
+			Size:    int64(len(variant.Data)),
+		})
+	}
+
+	// Исходный файл заменяется версией без метаданных под тем же именем
+	if err := media.App.Put(file.Key, file.ContentType, processed.Original); err != nil {
+		removeMediaFiles(keys)
+		return failMedia(file.ID, err)
+	}
+
+	deleted := false
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Media{}).
+			Where("id = ? AND status = ?", file.ID, model.MediaProcessing).
+			Updates(map[string]interface{}{
+				"status":   model.MediaReady,
+				"width":    processed.Width,
+				"height":   processed.Height,
+				"blurhash": processed.Blurhash,
+				"size":     int64(len(processed.Original)),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Файл удалили во время обработки или его уже обработал другой обработчик
+			var count int64
+			if err := tx.Model(&model.Media{}).Where("id = ?", file.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			deleted = count == 0
+			return nil
+		}
+
+		if err := tx.Where("media_id = ?", file.ID).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) > 0 {
+			return tx.Create(&variants).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении обработанного файла: %v", err)
+	}
+	if deleted {
+		removeMediaFiles(append(keys, file.Key))
+	}
+	return nil
+}
+
+// failMedia отмечает файл как необработанный. Такой файл не отдается читателям, но его можно удалить из библиотеки.
+func failMedia(id uint, cause error) error {
+	err := db.App.Model(&model.Media{}).
+		Where("id = ? AND status = ?", id, model.MediaProcessing).
+		Update("status", model.MediaFailed).Error
+	if err != nil {
+		log.App.Error("Ошибка при сохранении статуса файла ", id, ": ", err)
+	}
+	return cause
+}
+
+// PendingMedia возвращает ID файлов, ожидающих обработки, начиная с самых старых
+func PendingMedia() ([]uint, error) {
+	var ids []uint
+	if err := db.App.Model(&model.Media{}).Where("status = ?", model.MediaProcessing).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при получении необработанных файлов: %v", err)
+	}
+	return ids, nil
+}
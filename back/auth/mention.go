@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"app/markdown"
+	"app/model"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mentionedUsers возвращает пользователей, упомянутых в тексте автора authorID.
+// Упоминания пользователей, заблокировавших автора, отбрасываются.
+func mentionedUsers(tx *gorm.DB, source string, authorID uint) ([]model.User, error) {
+	usernames := markdown.App.Mentions(source)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	var users []model.User
+	err := tx.Where("username IN ?", usernames).
+		Where("id NOT IN (SELECT blocker_id FROM blocks WHERE blocked_id = ?)", authorID).
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при поиске упомянутых пользователей: %v", err)
+	}
+	return users, nil
+}
+
+// mentionProfiles возвращает ссылки на профили пользователей, упомянутых в тексте автора authorID
+func mentionProfiles(tx *gorm.DB, source string, authorID uint) (map[string]string, error) {
+	users, err := mentionedUsers(tx, source, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]string, len(users))
+	for _, user := range users {
+		profiles[user.Username] = profileURL(user.ID)
+	}
+	return profiles, nil
+}
+
+// renderMentions рендерит текст автора authorID, заменяя упоминания ссылками на профили
+func renderMentions(tx *gorm.DB, source string, authorID uint) (string, error) {
+	profiles, err := mentionProfiles(tx, source, authorID)
+	if err != nil {
+		return "", err
+	}
+	return markdown.App.RenderMentions(source, profiles)
+}
+
+// saveMentions сохраняет упоминания из текста поста или комментария автора authorID.
+// commentID 0 означает текст поста. Упоминания, удаленные из текста, удаляются.
+func saveMentions(tx *gorm.DB, postID, commentID uint, source string, authorID uint) error {
+	users, err := mentionedUsers(tx, source, authorID)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]uint, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	stale := tx.Where("post_id = ? AND comment_id = ?", postID, commentID)
+	if len(userIDs) > 0 {
+		stale = stale.Where("user_id NOT IN ?", userIDs)
+	}
+	if err := stale.Delete(&model.Mention{}).Error; err != nil {
+		return fmt.Errorf("Ошибка при сохранении упоминаний: %v", err)
+	}
+
+	for _, userID := range userIDs {
+		mention := model.Mention{PostID: postID, CommentID: commentID, UserID: userID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mention).Error; err != nil {
+			return fmt.Errorf("Ошибка при сохранении упоминаний: %v", err)
+		}
+	}
+	return nil
+}
+
+// notifyMentions уведомляет пользователей, упомянутых в посте или комментарии. Об упоминании в одном посте
+// или комментарии пользователь узнает один раз, сколько бы раз ни менялся текст.
+func notifyMentions(tx *gorm.DB, postID, commentID, actorID uint) error {
+	var mentions []model.Mention
+	err := tx.Where("post_id = ? AND comment_id = ?", postID, commentID).
+		Where("user_id NOT IN (SELECT user_id FROM notifications WHERE type = ? AND post_id = ? AND comment_id = ?)",
+			model.NotificationMention, postID, commentID).
+		Find(&mentions).Error
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+
+	for _, mention := range mentions {
+		err := notify(tx, model.Notification{
+			UserID:    mention.UserID,
+			ActorID:   actorID,
+			Type:      model.NotificationMention,
+			PostID:    postID,
+			CommentID: commentID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
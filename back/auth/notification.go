@@ -0,0 +1,413 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/live"
+	"app/log"
+	"app/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// outboxKey ключ контекста транзакции, в котором хранятся неотправленные живые уведомления
+type outboxKey struct{}
+
+// liveOutbox живые уведомления, созданные в транзакции
+type liveOutbox struct {
+	pending []pendingNotification
+}
+
+// pendingNotification живое уведомление, ожидающее фиксации транзакции
+type pendingNotification struct {
+	userID       uint
+	notification model.LiveNotificationJson
+}
+
+// withNotifications выполняет fn в транзакции и отправляет созданные в ней живые уведомления только после фиксации.
+// Иначе клиент, получивший уведомление, может не найти его в списке, а при откате получит несуществующее уведомление.
+func withNotifications(fn func(tx *gorm.DB) error) error {
+	outbox := &liveOutbox{}
+	err := db.App.WithContext(context.WithValue(context.Background(), outboxKey{}, outbox)).Transaction(fn)
+	if err != nil {
+		return err
+	}
+	for _, pending := range outbox.pending {
+		live.App.PublishNotification(pending.userID, pending.notification)
+	}
+	return nil
+}
+
+// notify сохраняет уведомление. Уведомления о собственных действиях пользователя не создаются.
+// Все события, о которых нужно сообщить пользователю, проходят через notify или notifyLike.
+func notify(tx *gorm.DB, notification model.Notification) error {
+	if notification.UserID == 0 || notification.UserID == notification.ActorID {
+		return nil
+	}
+	if err := tx.Create(&notification).Error; err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+	publishNotification(tx, &notification, notification.ID)
+	return nil
+}
+
+// publishNotification сообщает получателю о новом уведомлении через живые обновления.
+// headID - ID уведомления, которое показывается пользователю. В транзакции withNotifications
+// уведомление откладывается до фиксации.
+func publishNotification(tx *gorm.DB, notification *model.Notification, headID uint) {
+	event := model.LiveNotificationJson{
+		ID:     headID,
+		Type:   notification.Type,
+		PostID: notification.PostID,
+	}
+	if outbox, ok := tx.Statement.Context.Value(outboxKey{}).(*liveOutbox); ok {
+		outbox.pending = append(outbox.pending, pendingNotification{userID: notification.UserID, notification: event})
+		return
+	}
+	live.App.PublishNotification(notification.UserID, event)
+}
+
+// notifyLike сообщает автору поста о лайке. Если непрочитанное уведомление о лайках этого поста появилось
+// не раньше чем GroupWindow минут назад, лайк добавляется в его группу, а уведомление поднимается наверх.
+func notifyLike(tx *gorm.DB, authorID, actorID, postID uint) error {
+	if authorID == 0 || authorID == actorID {
+		return nil
+	}
+
+	window := time.Duration(config.File.NotificationConfig.GroupWindow) * time.Minute
+	var head model.Notification
+	err := tx.Where("user_id = ? AND type = ? AND post_id = ? AND group_id = 0 AND read = ? AND created_at >= ?",
+		authorID, model.NotificationLike, postID, false, time.Now().Add(-window)).
+		Order("id DESC").First(&head).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notify(tx, model.Notification{
+			UserID:  authorID,
+			ActorID: actorID,
+			Type:    model.NotificationLike,
+			PostID:  postID,
+		})
+	} else if err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+
+	// Пользователь, снявший и снова поставивший лайк, не добавляется в группу второй раз
+	var seen int64
+	err = tx.Model(&model.Notification{}).Where("(id = ? OR group_id = ?) AND actor_id = ?", head.ID, head.ID, actorID).Count(&seen).Error
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+	if seen == 0 {
+		notification := model.Notification{
+			UserID:  authorID,
+			ActorID: actorID,
+			Type:    model.NotificationLike,
+			PostID:  postID,
+			GroupID: head.ID,
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+		}
+		publishNotification(tx, &notification, head.ID)
+	}
+
+	if err := tx.Model(&head).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+	return nil
+}
+
+// pluralRu выбирает форму слова для числа n: одна, две или пять штук
+func pluralRu(n int64, one, few, many string) string {
+	if n%100 >= 11 && n%100 <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	default:
+		return many
+	}
+}
+
+// notificationText возвращает текст уведомления
+func notificationText(notification *model.NotificationJson) string {
+	actor := notification.ActorName
+	if notification.Others > 0 {
+		actor = fmt.Sprintf("%s и еще %d %s", actor, notification.Others,
+			pluralRu(notification.Others, "пользователь", "пользователя", "пользователей"))
+	}
+
+	switch notification.Type {
+	case model.NotificationLike:
+		if notification.Others > 0 {
+			return fmt.Sprintf("%s: новые лайки к посту «%s»", actor, notification.PostTitle)
+		}
+		return fmt.Sprintf("%s: новый лайк к посту «%s»", actor, notification.PostTitle)
+	case model.NotificationComment:
+		return fmt.Sprintf("%s: новый комментарий к посту «%s»", actor, notification.PostTitle)
+	case model.NotificationFollow:
+		return fmt.Sprintf("Новый подписчик: %s", actor)
+	case model.NotificationMention:
+		if notification.CommentID != 0 {
+			return fmt.Sprintf("%s упоминает вас в комментарии к посту «%s»", actor, notification.PostTitle)
+		}
+		return fmt.Sprintf("%s упоминает вас в посте «%s»", actor, notification.PostTitle)
+	case model.NotificationReport:
+		if notification.Outcome == model.ReportDismissed {
+			return "Ваша жалоба рассмотрена: нарушений не найдено"
+		}
+		return "Ваша жалоба рассмотрена: приняты меры. Спасибо!"
+	default:
+		return ""
+	}
+}
+
+// unreadNotifications возвращает количество непрочитанных уведомлений пользователя
+func unreadNotifications(userID uint) (int64, error) {
+	var unread int64
+	err := db.App.Model(&model.Notification{}).
+		Where("user_id = ? AND group_id = 0 AND read = ?", userID, false).
+		Count(&unread).Error
+	return unread, err
+}
+
+// notificationsToJson преобразует уведомления в формат ответа. Для групп лайков подставляется
+// последний поставивший лайк пользователь и количество остальных.
+func notificationsToJson(notifications []model.Notification) ([]model.NotificationJson, error) {
+	headIDs := make([]uint, 0, len(notifications))
+	for _, notification := range notifications {
+		headIDs = append(headIDs, notification.ID)
+	}
+
+	// Последнее событие и количество пользователей каждой группы
+	var groups []struct {
+		Head   uint
+		LastID uint
+		Actors int64
+	}
+	err := db.App.Model(&model.Notification{}).
+		Select("CASE WHEN group_id = 0 THEN id ELSE group_id END AS head, MAX(id) AS last_id, COUNT(DISTINCT actor_id) AS actors").
+		Where("id IN ? OR group_id IN ?", headIDs, headIDs).
+		Group("CASE WHEN group_id = 0 THEN id ELSE group_id END").
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	lastIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		lastIDs = append(lastIDs, group.LastID)
+	}
+	var last []model.Notification
+	if err := db.App.Where("id IN ?", lastIDs).Find(&last).Error; err != nil {
+		return nil, err
+	}
+	lastActors := make(map[uint]uint, len(last))
+	for _, notification := range last {
+		lastActors[notification.ID] = notification.ActorID
+	}
+
+	actorIDs := make([]uint, 0, len(groups))
+	type groupInfo struct {
+		actorID uint
+		others  int64
+	}
+	groupsByHead := make(map[uint]groupInfo, len(groups))
+	for _, group := range groups {
+		info := groupInfo{actorID: lastActors[group.LastID], others: group.Actors - 1}
+		groupsByHead[group.Head] = info
+		actorIDs = append(actorIDs, info.actorID)
+	}
+
+	postIDs := make([]uint, 0, len(notifications))
+	reportIDs := make([]uint, 0)
+	for _, notification := range notifications {
+		if notification.PostID != 0 {
+			postIDs = append(postIDs, notification.PostID)
+		}
+		if notification.ReportID != 0 {
+			reportIDs = append(reportIDs, notification.ReportID)
+		}
+	}
+
+	var actors []model.User
+	if err := db.App.Where("id IN ?", actorIDs).Find(&actors).Error; err != nil {
+		return nil, err
+	}
+	actorNames := make(map[uint]string, len(actors))
+	for _, actor := range actors {
+		actorNames[actor.ID] = actor.Name
+	}
+
+	// Уведомления о постах из корзины остаются до окончательного удаления поста
+	var posts []model.Post
+	if err := db.App.Unscoped().Select("id, title, slug").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	postsByID := make(map[uint]*model.Post, len(posts))
+	for i := range posts {
+		postsByID[posts[i].ID] = &posts[i]
+	}
+
+	var reports []model.Report
+	if err := db.App.Select("id, status").Where("id IN ?", reportIDs).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	outcomes := make(map[uint]string, len(reports))
+	for _, report := range reports {
+		outcomes[report.ID] = report.Status
+	}
+
+	result := make([]model.NotificationJson, 0, len(notifications))
+	for _, notification := range notifications {
+		group := groupsByHead[notification.ID]
+		item := model.NotificationJson{
+			ID:        notification.ID,
+			Type:      notification.Type,
+			ActorID:   group.actorID,
+			ActorName: actorNames[group.actorID],
+			Others:    group.others,
+			PostID:    notification.PostID,
+			CommentID: notification.CommentID,
+			ReportID:  notification.ReportID,
+			Outcome:   outcomes[notification.ReportID],
+			Read:      notification.Read,
+			Date:      notification.UpdatedAt.Format("02.01.2006 15:04"),
+		}
+		if post, ok := postsByID[notification.PostID]; ok {
+			item.PostTitle = post.Title
+			item.PostURL = postURL(post.Slug)
+		}
+		item.Text = notificationText(&item)
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetNotifications возвращает страницу уведомлений пользователя, начиная с последних событий
+func GetNotifications(jwtToken string, req model.GetNotificationsRequest) (*model.GetNotificationsResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Model(&model.Notification{}).Where("user_id = ? AND group_id = 0", token.UserId)
+	if req.UnreadOnly {
+		query = query.Where("read = ?", false)
+	}
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var notifications []model.Notification
+	if err := query.Order("updated_at DESC, id DESC").Offset(offset).Limit(limit).Find(&notifications).Error; err != nil {
+		log.App.Error("Ошибка при получении уведомлений: ", err)
+		return nil, err
+	}
+
+	result, err := notificationsToJson(notifications)
+	if err != nil {
+		log.App.Error("Ошибка при получении уведомлений: ", err)
+		return nil, err
+	}
+
+	unread, err := unreadNotifications(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetNotificationsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Уведомления получены",
+		},
+		Notifications: result,
+		Page:          page,
+		Total:         total,
+		Unread:        unread,
+	}, nil
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным. Следующий лайк того же поста начнет новую группу.
+func MarkNotificationRead(jwtToken string, req model.MarkNotificationReadRequest) (*model.MarkNotificationsReadResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	res := db.App.Model(&model.Notification{}).
+		Where("user_id = ? AND (id = ? OR group_id = ?)", token.UserId, req.ID, req.ID).
+		UpdateColumn("read", true)
+	if res.Error != nil {
+		log.App.Error("Ошибка при отметке уведомления: ", res.Error)
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("Уведомление не найдено")
+	}
+
+	return markNotificationsReadResponse(token.UserId, "Уведомление прочитано")
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя
+func MarkAllNotificationsRead(jwtToken string) (*model.MarkNotificationsReadResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Model(&model.Notification{}).
+		Where("user_id = ? AND read = ?", token.UserId, false).
+		UpdateColumn("read", true).Error
+	if err != nil {
+		log.App.Error("Ошибка при отметке уведомлений: ", err)
+		return nil, err
+	}
+
+	return markNotificationsReadResponse(token.UserId, "Все уведомления прочитаны")
+}
+
+// markNotificationsReadResponse формирует ответ с оставшимся количеством непрочитанных уведомлений
+func markNotificationsReadResponse(userID uint, message string) (*model.MarkNotificationsReadResponse, error) {
+	unread, err := unreadNotifications(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MarkNotificationsReadResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+		Unread: unread,
+	}, nil
+}
+
+// GetUnreadNotificationsCount возвращает количество непрочитанных уведомлений пользователя
+func GetUnreadNotificationsCount(jwtToken string) (*model.GetUnreadNotificationsCountResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := unreadNotifications(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetUnreadNotificationsCountResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Количество непрочитанных уведомлений получено",
+		},
+		Unread: unread,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"app/model"
+	"app/pow"
+	"fmt"
+	"strings"
+)
+
+// powSubject возвращает адрес, к которому привязывается решение задачи
+func powSubject(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkProofOfWork проверяет решение задачи перед отправкой письма на адрес email, не расходуя задачу.
+// Если задачи отключены, проверка пропускается.
+func checkProofOfWork(scope, email string, proof model.ProofOfWork) error {
+	if pow.App == nil {
+		return nil
+	}
+	return pow.App.Verify(proof.Challenge, scope, powSubject(email), proof.Solution)
+}
+
+// spendProofOfWork отмечает задачу использованной непосредственно перед отправкой письма.
+// До этого ошибка в почте или пароле не заставляет решать задачу заново.
+func spendProofOfWork(proof model.ProofOfWork) error {
+	if pow.App == nil {
+		return nil
+	}
+	return pow.App.Spend(proof.Challenge)
+}
+
+// GetPowChallenge выдает задачу proof-of-work для регистрации или сброса пароля.
+// Решение - строка, при которой SHA-256 от "задача:почта в нижнем регистре:решение" начинается с difficulty нулевых бит.
+func GetPowChallenge(req model.PowChallengeRequest) (*model.PowChallengeResponse, error) {
+	switch req.Scope {
+	case model.PowRegister, model.PowResetPassword:
+	default:
+		return nil, fmt.Errorf("Неизвестное назначение задачи: %s", req.Scope)
+	}
+
+	if pow.App == nil {
+		return &model.PowChallengeResponse{
+			Response: model.Response{
+				Status:  true,
+				Message: "Задача проверки не требуется",
+			},
+		}, nil
+	}
+
+	challenge, difficulty, expires, err := pow.App.Issue(req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PowChallengeResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Задача проверки выдана",
+		},
+		Enabled:    true,
+		Challenge:  challenge,
+		Difficulty: difficulty,
+		Algorithm:  pow.Algorithm,
+		ExpiresAt:  expires.Format("02.01.2006 15:04"),
+	}, nil
+}
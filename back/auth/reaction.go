@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/live"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reactionKinds возвращает доступные виды реакций. Лайк доступен всегда.
+func reactionKinds() []string {
+	kinds := config.File.ReactionConfig.Kinds
+	if !slices.Contains(kinds, model.ReactionLike) {
+		kinds = append([]string{model.ReactionLike}, kinds...)
+	}
+	return kinds
+}
+
+// validateReaction проверяет, что вид реакции есть в списке доступных
+func validateReaction(kind string) error {
+	if !slices.Contains(reactionKinds(), kind) {
+		return fmt.Errorf("Неизвестный вид реакции: %s", kind)
+	}
+	return nil
+}
+
+// postReactions возвращает количество реакций по видам для каждого поста и реакции пользователя viewerID.
+// Для анонимного пользователя (viewerID = 0) реакции пользователя не заполняются.
+func postReactions(postIDs []uint, viewerID uint) (map[uint]map[string]int, map[uint][]string, error) {
+	var counts []struct {
+		PostID uint
+		Kind   string
+		Count  int
+	}
+	err := db.App.Model(&model.Like{}).
+		Select("post_id, kind, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id, kind").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("Ошибка при получении реакций: %v", err)
+	}
+
+	reactions := make(map[uint]map[string]int, len(postIDs))
+	for _, id := range postIDs {
+		reactions[id] = map[string]int{}
+	}
+	for _, count := range counts {
+		reactions[count.PostID][count.Kind] = count.Count
+	}
+
+	mine := make(map[uint][]string)
+	if viewerID != 0 {
+		var likes []model.Like
+		err := db.App.Select("post_id, kind").Where("user_id = ? AND post_id IN ?", viewerID, postIDs).Order("id").Find(&likes).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("Ошибка при получении реакций пользователя: %v", err)
+		}
+		for _, like := range likes {
+			mine[like.PostID] = append(mine[like.PostID], like.Kind)
+		}
+	}
+	for _, id := range postIDs {
+		if mine[id] == nil {
+			mine[id] = []string{}
+		}
+	}
+
+	return reactions, mine, nil
+}
+
+// fillReactions заполняет реакции поста и реакции пользователя viewerID
+func fillReactions(post *model.PostJson, viewerID uint) error {
+	reactions, mine, err := postReactions([]uint{post.ID}, viewerID)
+	if err != nil {
+		return err
+	}
+	post.Reactions = reactions[post.ID]
+	post.MyReactions = mine[post.ID]
+	return nil
+}
+
+// putReaction ставит реакцию пользователя на пост. Повторная реакция ничего не меняет.
+// Уникальный индекс не дает создать две одинаковые реакции при одновременных запросах,
+// а счетчик лайков меняется в той же транзакции, что и сама реакция.
+func putReaction(userID, postID uint, kind string) error {
+	var post model.Post
+	if err := db.App.Select("id, author_id, status").First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Пост не найден")
+		}
+		log.App.Error("Ошибка при получении поста: ", err)
+		return err
+	}
+	if post.Status != model.PostPublished {
+		return fmt.Errorf("Пост не найден")
+	}
+	if err := checkNotBlocked(userID, post.AuthorID); err != nil {
+		return err
+	}
+
+	changed := false
+	err := withNotifications(func(tx *gorm.DB) error {
+		like := model.Like{
+			UserID: userID,
+			PostID: postID,
+			Kind:   kind,
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&like)
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected > 0
+		if !changed || kind != model.ReactionLike {
+			return nil
+		}
+
+		err := tx.Model(&model.Post{}).Where("id = ?", postID).
+			UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error
+		if err != nil {
+			return err
+		}
+
+		return notifyLike(tx, post.AuthorID, userID, postID)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при постановке реакции: ", err)
+		return err
+	}
+
+	if changed {
+		publishReactions(postID)
+	}
+	return nil
+}
+
+// removeReaction снимает реакцию пользователя с поста. Снятие отсутствующей реакции ничего не меняет.
+func removeReaction(userID, postID uint, kind string) error {
+	changed := false
+	err := db.App.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("user_id = ? AND post_id = ? AND kind = ? AND deleted_at IS NULL", userID, postID, kind).
+			Delete(&model.Like{})
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected > 0
+		if !changed || kind != model.ReactionLike {
+			return nil
+		}
+
+		// Условие не дает счетчику стать отрицательным, если он разошелся с таблицей лайков
+		return tx.Model(&model.Post{}).Where("id = ? AND likes_count > 0", postID).
+			UpdateColumn("likes_count", gorm.Expr("likes_count - 1")).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при снятии реакции: ", err)
+		return err
+	}
+
+	if changed {
+		publishReactions(postID)
+	}
+	return nil
+}
+
+// publishReactions сообщает клиентам, которые следят за постом, новое количество реакций
+func publishReactions(postID uint) {
+	reactions, _, err := postReactions([]uint{postID}, 0)
+	if err != nil {
+		log.App.Error("Ошибка при отправке реакций в живые обновления: ", err)
+		return
+	}
+	var post model.Post
+	if err := db.App.Select("id, likes_count").First(&post, postID).Error; err != nil {
+		log.App.Error("Ошибка при отправке реакций в живые обновления: ", err)
+		return
+	}
+
+	live.App.PublishLikes(model.LiveLikesJson{
+		PostID:    postID,
+		Likes:     post.LikesCount,
+		Reactions: reactions[postID],
+	})
+}
+
+// ReconcileLikesCount пересчитывает количество лайков всех постов по таблице лайков.
+// Лайки поста из корзины скрыты вместе с постом, но учитываются. Возвращает количество исправленных постов.
+func ReconcileLikesCount() (int, error) {
+	const likes = `(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND likes.kind = ?
+		AND (likes.deleted_at IS NULL OR posts.deleted_at IS NOT NULL))`
+
+	res := db.App.Exec("UPDATE posts SET likes_count = "+likes+" WHERE likes_count <> "+likes,
+		model.ReactionLike, model.ReactionLike)
+	if res.Error != nil {
+		log.App.Error("Ошибка при пересчете лайков: ", res.Error)
+		return 0, res.Error
+	}
+
+	log.App.Info(fmt.Sprintf("Количество лайков пересчитано, исправлено постов: %d", res.RowsAffected))
+	return int(res.RowsAffected), nil
+}
+
+// reactionResponse формирует ответ с текущими реакциями поста
+func reactionResponse(userID, postID uint, message string) (*model.ReactionResponse, error) {
+	reactions, mine, err := postReactions([]uint{postID}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReactionResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+		Reactions:   reactions[postID],
+		MyReactions: mine[postID],
+	}, nil
+}
+
+// PutReaction ставит реакцию на пост. Каждую реакцию пользователь может поставить посту один раз.
+func PutReaction(jwtToken string, req model.ReactionRequest) (*model.ReactionResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReaction(req.Kind); err != nil {
+		return nil, err
+	}
+
+	if err := putReaction(token.UserId, req.PostID, req.Kind); err != nil {
+		return nil, err
+	}
+
+	return reactionResponse(token.UserId, req.PostID, "Реакция поставлена")
+}
+
+// DownReaction снимает реакцию с поста
+func DownReaction(jwtToken string, req model.ReactionRequest) (*model.ReactionResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReaction(req.Kind); err != nil {
+		return nil, err
+	}
+
+	if err := removeReaction(token.UserId, req.PostID, req.Kind); err != nil {
+		return nil, err
+	}
+
+	return reactionResponse(token.UserId, req.PostID, "Реакция снята")
+}
+
+// GetReactionKinds возвращает доступные виды реакций
+func GetReactionKinds() *model.GetReactionKindsResponse {
+	return &model.GetReactionKindsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Виды реакций получены",
+		},
+		Kinds: reactionKinds(),
+	}
+}
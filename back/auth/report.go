@@ -0,0 +1,611 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// reportPreviewLength длина отрывка комментария в очереди жалоб
+const reportPreviewLength = 200
+
+// canModerate проверяет, является ли владелец токена администратором или модератором
+func canModerate(token *model.Token) bool {
+	return token != nil && (token.Role == model.AdminRole || token.Role == model.ModeratorRole)
+}
+
+// checkSuspended проверяет, не заблокирован ли пользователь по решению модератора
+func checkSuspended(user *model.User) error {
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		return fmt.Errorf("Аккаунт заблокирован до %s", user.SuspendedUntil.Format("02.01.2006 15:04"))
+	}
+	return nil
+}
+
+// reportTarget проверяет объект жалобы и возвращает новую жалобу на него. На свои посты, комментарии
+// и на самого себя пожаловаться нельзя, как и на объекты, которые пользователь не видит.
+func reportTarget(token *model.Token, targetType string, targetID uint) (*model.Report, error) {
+	report := &model.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Status:     model.ReportOpen,
+	}
+
+	switch targetType {
+	case model.ReportPost:
+		var post model.Post
+		if err := db.App.First(&post, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Пост не найден")
+			}
+			return nil, err
+		}
+		if err := checkPostVisible(token, &post); err != nil {
+			return nil, err
+		}
+		report.TargetAuthorID = post.AuthorID
+		report.PostID = post.ID
+	case model.ReportComment:
+		comment, post, err := getCommentPost(targetID)
+		if err != nil {
+			return nil, err
+		}
+		if !commentVisible(comment, token, post) {
+			return nil, fmt.Errorf("Комментарий не найден")
+		}
+		report.TargetAuthorID = comment.AuthorID
+		report.PostID = post.ID
+	case model.ReportUser:
+		if err := db.App.First(&model.User{}, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Пользователь не найден")
+			}
+			return nil, err
+		}
+		report.TargetAuthorID = targetID
+	default:
+		return nil, fmt.Errorf("Неизвестный объект жалобы: %s", targetType)
+	}
+
+	if report.TargetAuthorID == token.UserId {
+		return nil, fmt.Errorf("Нельзя пожаловаться на самого себя")
+	}
+	return report, nil
+}
+
+// fileReport добавляет жалобу пользователя reporterID к открытой жалобе на объект report или открывает новую.
+// Повторная жалоба того же пользователя заменяет его причину и пояснение. reporterID 0 - фильтр содержимого.
+func fileReport(tx *gorm.DB, report *model.Report, reporterID uint, reason, text string) error {
+	held := report.Held
+	err := tx.Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, model.ReportOpen).
+		First(report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tx.Create(report).Error
+	} else if err == nil && held && !report.Held {
+		report.Held = true
+		err = tx.Model(report).UpdateColumn("held", true).Error
+	}
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении жалобы: %v", err)
+	}
+
+	var entry model.ReportEntry
+	err = tx.Where("report_id = ? AND reporter_id = ?", report.ID, reporterID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		entry = model.ReportEntry{ReportID: report.ID, ReporterID: reporterID, Reason: reason, Text: text}
+		err = tx.Create(&entry).Error
+	} else if err == nil {
+		err = tx.Model(&entry).Updates(map[string]interface{}{"reason": reason, "text": text}).Error
+	}
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении жалобы: %v", err)
+	}
+
+	// Количество жалоб считается по пользователям, поэтому повторные жалобы его не увеличивают
+	err = tx.Model(report).UpdateColumn("reports_count",
+		gorm.Expr("(SELECT COUNT(*) FROM report_entries WHERE report_id = ?)", report.ID)).Error
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении жалобы: %v", err)
+	}
+	return nil
+}
+
+// ReportContent принимает жалобу на пост, комментарий или пользователя. Пока жалоба на объект не рассмотрена,
+// новые жалобы добавляются к ней, а повторная жалоба того же пользователя заменяет его причину и пояснение.
+func ReportContent(jwtToken string, req model.ReportContentRequest) (*model.ReportContentResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(model.ReportReasons, req.Reason) {
+		return nil, fmt.Errorf("Неизвестная причина жалобы: %s", req.Reason)
+	}
+	text := strings.TrimSpace(req.Text)
+	if maxLength := config.File.ReportConfig.TextMaxLength; utf8.RuneCountInString(text) > maxLength {
+		return nil, fmt.Errorf("Пояснение не должно превышать %d символов", maxLength)
+	}
+	if req.Reason == model.ReasonOther && text == "" {
+		return nil, fmt.Errorf("Опишите причину жалобы")
+	}
+
+	report, err := reportTarget(token, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		return fileReport(tx, report, token.UserId, req.Reason, text)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при создании жалобы: ", err)
+		return nil, err
+	}
+
+	return &model.ReportContentResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Жалоба отправлена",
+		},
+	}, nil
+}
+
+// GetReportReasons возвращает допустимые причины жалоб
+func GetReportReasons() *model.GetReportReasonsResponse {
+	return &model.GetReportReasonsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Причины жалоб получены",
+		},
+		Reasons: model.ReportReasons,
+	}
+}
+
+// reportPreview возвращает отрывок текста для очереди жалоб
+func reportPreview(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= reportPreviewLength {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:reportPreviewLength])) + "…"
+}
+
+// reportsToJson преобразует жалобы в формат очереди модерации вместе с жалобами пользователей
+// и отрывками объектов. Удаленные посты, комментарии и пользователи тоже показываются.
+func reportsToJson(reports []model.Report) ([]model.ReportJson, error) {
+	reportIDs := make([]uint, 0, len(reports))
+	userIDs := make([]uint, 0, len(reports)*2)
+	postIDs := make([]uint, 0, len(reports))
+	commentIDs := make([]uint, 0, len(reports))
+	for _, report := range reports {
+		reportIDs = append(reportIDs, report.ID)
+		userIDs = append(userIDs, report.TargetAuthorID, report.AssigneeID)
+		if report.PostID != 0 {
+			postIDs = append(postIDs, report.PostID)
+		}
+		if report.TargetType == model.ReportComment {
+			commentIDs = append(commentIDs, report.TargetID)
+		}
+	}
+
+	var entries []model.ReportEntry
+	if err := db.App.Where("report_id IN ?", reportIDs).Order("created_at, id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.ReporterID)
+	}
+
+	var users []model.User
+	if err := db.App.Unscoped().Select("id, name").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	userNames := make(map[uint]string, len(users))
+	for _, user := range users {
+		userNames[user.ID] = user.Name
+	}
+
+	var posts []model.Post
+	if err := db.App.Unscoped().Select("id, title, slug").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	postsByID := make(map[uint]*model.Post, len(posts))
+	for i := range posts {
+		postsByID[posts[i].ID] = &posts[i]
+	}
+
+	var comments []model.Comment
+	if err := db.App.Unscoped().Select("id, body").Where("id IN ?", commentIDs).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	commentBodies := make(map[uint]string, len(comments))
+	for _, comment := range comments {
+		commentBodies[comment.ID] = comment.Body
+	}
+
+	entriesByReport := make(map[uint][]model.ReportEntryJson, len(reports))
+	reasonsByReport := make(map[uint]map[string]int, len(reports))
+	userNames[0] = "Фильтр содержимого"
+	for _, entry := range entries {
+		entriesByReport[entry.ReportID] = append(entriesByReport[entry.ReportID], model.ReportEntryJson{
+			ReporterID:   entry.ReporterID,
+			ReporterName: userNames[entry.ReporterID],
+			Reason:       entry.Reason,
+			Text:         entry.Text,
+			Date:         entry.CreatedAt.Format("02.01.2006 15:04"),
+		})
+		if reasonsByReport[entry.ReportID] == nil {
+			reasonsByReport[entry.ReportID] = make(map[string]int)
+		}
+		reasonsByReport[entry.ReportID][entry.Reason]++
+	}
+
+	result := make([]model.ReportJson, 0, len(reports))
+	for _, report := range reports {
+		item := model.ReportJson{
+			ID:               report.ID,
+			TargetType:       report.TargetType,
+			TargetID:         report.TargetID,
+			TargetAuthorID:   report.TargetAuthorID,
+			TargetAuthorName: userNames[report.TargetAuthorID],
+			PostID:           report.PostID,
+			Status:           report.Status,
+			ReportsCount:     report.ReportsCount,
+			Held:             report.Held,
+			Reasons:          reasonsByReport[report.ID],
+			Entries:          entriesByReport[report.ID],
+			AssigneeID:       report.AssigneeID,
+			AssigneeName:     userNames[report.AssigneeID],
+			Action:           report.Action,
+			Date:             report.CreatedAt.Format("02.01.2006 15:04"),
+		}
+		post, ok := postsByID[report.PostID]
+		if ok {
+			item.PostURL = postURL(post.Slug)
+		}
+		switch report.TargetType {
+		case model.ReportPost:
+			if ok {
+				item.Preview = post.Title
+			}
+		case model.ReportComment:
+			item.Preview = reportPreview(commentBodies[report.TargetID])
+		case model.ReportUser:
+			item.Preview = userNames[report.TargetID]
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetReportQueue возвращает страницу очереди жалоб. Открытые жалобы упорядочены по количеству
+// пожаловавшихся пользователей, рассмотренные - начиная с последних. Доступно администраторам и модераторам.
+func GetReportQueue(jwtToken string, req model.GetReportQueueRequest) (*model.GetReportQueueResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate(token) {
+		return nil, fmt.Errorf("Очередь жалоб доступна только модераторам")
+	}
+
+	query := db.App.Model(&model.Report{})
+	order := "updated_at DESC, id DESC"
+	switch req.Status {
+	case "", model.ReportOpen:
+		query = query.Where("status = ?", model.ReportOpen)
+		order = "reports_count DESC, created_at, id"
+	case model.ReportDismissed, model.ReportResolved:
+		query = query.Where("status = ?", req.Status)
+	case "all":
+	default:
+		return nil, fmt.Errorf("Неизвестный статус жалобы: %s", req.Status)
+	}
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if req.Reason != "" {
+		query = query.Where("id IN (SELECT report_id FROM report_entries WHERE reason = ?)", req.Reason)
+	}
+	if req.Unassigned {
+		query = query.Where("assignee_id = 0")
+	} else if req.AssigneeID != 0 {
+		query = query.Where("assignee_id = ?", req.AssigneeID)
+	}
+
+	page, limit, offset := pagination(req.Page, req.Limit)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var reports []model.Report
+	if err := query.Order(order).Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		log.App.Error("Ошибка при получении очереди жалоб: ", err)
+		return nil, err
+	}
+
+	result, err := reportsToJson(reports)
+	if err != nil {
+		log.App.Error("Ошибка при получении очереди жалоб: ", err)
+		return nil, err
+	}
+
+	return &model.GetReportQueueResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Очередь жалоб получена",
+		},
+		Reports: result,
+		Page:    page,
+		Total:   total,
+	}, nil
+}
+
+// AssignReport назначает открытую жалобу администратору или модератору либо снимает назначение
+func AssignReport(jwtToken string, req model.AssignReportRequest) (*model.AssignReportResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate(token) {
+		return nil, fmt.Errorf("Назначать жалобы могут только модераторы")
+	}
+
+	if req.AssigneeID != 0 {
+		var assignee model.User
+		if err := db.App.First(&assignee, req.AssigneeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Пользователь не найден")
+			}
+			return nil, err
+		}
+		if assignee.Role != model.AdminRole && assignee.Role != model.ModeratorRole {
+			return nil, fmt.Errorf("Жалобу можно назначить только модератору")
+		}
+	}
+
+	var report model.Report
+	if err := db.App.First(&report, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Жалоба не найдена")
+		}
+		return nil, err
+	}
+	if report.Status != model.ReportOpen {
+		return nil, fmt.Errorf("Жалоба уже рассмотрена")
+	}
+
+	if err := db.App.Model(&report).UpdateColumn("assignee_id", req.AssigneeID).Error; err != nil {
+		log.App.Error("Ошибка при назначении жалобы: ", err)
+		return nil, err
+	}
+
+	message := "Жалоба назначена"
+	if req.AssigneeID == 0 {
+		message = "Назначение снято"
+	}
+	return &model.AssignReportResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+	}, nil
+}
+
+// hideReportedPost скрывает пост по жалобе, а при удалении еще и переносит его в корзину.
+// Восстановленный из корзины пост остается скрытым.
+func hideReportedPost(tx *gorm.DB, postID uint, remove bool) error {
+	var post model.Post
+	err := tx.Unscoped().Preload("Tags").First(&post, postID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Пост уже удален окончательно
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Model(&post).UpdateColumn("status", model.PostHidden).Error; err != nil {
+		return fmt.Errorf("Ошибка при скрытии поста: %v", err)
+	}
+	if !remove || post.DeletedAt.Valid {
+		return nil
+	}
+	return trashPost(tx, &post)
+}
+
+// hideReportedComment скрывает или удаляет комментарий по жалобе. Скрытый комментарий остается в дереве без текста.
+func hideReportedComment(tx *gorm.DB, commentID uint, remove bool) error {
+	var comment model.Comment
+	err := tx.First(&comment, commentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Комментарий уже удален
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if remove {
+		return deleteComment(tx, &comment)
+	}
+	if comment.Status == model.CommentHidden {
+		return nil
+	}
+	if err := tx.Model(&comment).UpdateColumn("status", model.CommentHidden).Error; err != nil {
+		return fmt.Errorf("Ошибка при скрытии комментария: %v", err)
+	}
+	if comment.Status != model.CommentApproved {
+		return nil
+	}
+	return changeCommentsCount(tx, comment.PostID, -1)
+}
+
+// suspendUser блокирует пользователя на days дней. Администратора заблокировать нельзя.
+func suspendUser(tx *gorm.DB, userID uint, days int) error {
+	var user model.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Пользователь не найден")
+		}
+		return err
+	}
+	if user.Role == model.AdminRole {
+		return fmt.Errorf("Нельзя заблокировать администратора")
+	}
+
+	until := time.Now().AddDate(0, 0, days)
+	if err := tx.Model(&user).UpdateColumn("suspended_until", until).Error; err != nil {
+		return fmt.Errorf("Ошибка при блокировке пользователя: %v", err)
+	}
+	return nil
+}
+
+// notifyReporters сообщает пожаловавшимся пользователям о решении по жалобе
+func notifyReporters(tx *gorm.DB, report *model.Report) error {
+	var entries []model.ReportEntry
+	if err := tx.Where("report_id = ?", report.ID).Find(&entries).Error; err != nil {
+		return fmt.Errorf("Ошибка при создании уведомления: %v", err)
+	}
+
+	var commentID uint
+	if report.TargetType == model.ReportComment {
+		commentID = report.TargetID
+	}
+	for _, entry := range entries {
+		err := notify(tx, model.Notification{
+			UserID:    entry.ReporterID,
+			Type:      model.NotificationReport,
+			PostID:    report.PostID,
+			CommentID: commentID,
+			ReportID:  report.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseHeldContent публикует пост или комментарий, задержанный фильтром, после отклонения жалобы.
+// Упомянутые в нем пользователи уведомляются при публикации.
+func releaseHeldContent(tx *gorm.DB, report *model.Report) error {
+	switch report.TargetType {
+	case model.ReportPost:
+		res := tx.Model(&model.Post{}).Where("id = ? AND status = ?", report.TargetID, model.PostPending).
+			UpdateColumn("status", model.PostPublished)
+		if res.Error != nil {
+			return fmt.Errorf("Ошибка при публикации поста: %v", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		return notifyMentions(tx, report.TargetID, 0, report.TargetAuthorID)
+	case model.ReportComment:
+		var comment model.Comment
+		err := tx.First(&comment, report.TargetID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return approveComment(tx, &comment, model.CommentHeld)
+	}
+	return nil
+}
+
+// ResolveReport принимает решение по открытой жалобе: отклоняет ее, скрывает или удаляет пост или комментарий
+// либо блокирует автора. Задержанное фильтром содержимое публикуется, если жалобу отклонить. Пожаловавшиеся пользователи получают уведомление о решении.
+func ResolveReport(jwtToken string, req model.ResolveReportRequest) (*model.ResolveReportResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate(token) {
+		return nil, fmt.Errorf("Рассматривать жалобы могут только модераторы")
+	}
+
+	var report model.Report
+	if err := db.App.First(&report, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Жалоба не найдена")
+		}
+		return nil, err
+	}
+
+	status := model.ReportResolved
+	switch req.Action {
+	case model.ReportActionDismiss:
+		status = model.ReportDismissed
+	case model.ReportActionHide, model.ReportActionDelete:
+		if report.TargetType == model.ReportUser {
+			return nil, fmt.Errorf("Пользователя можно только заблокировать")
+		}
+	case model.ReportActionSuspend:
+	default:
+		return nil, fmt.Errorf("Неизвестное решение по жалобе: %s", req.Action)
+	}
+
+	days := req.SuspendDays
+	if days <= 0 {
+		days = config.File.ReportConfig.SuspendDays
+	}
+
+	err = withNotifications(func(tx *gorm.DB) error {
+		// Условие на статус не дает двум модераторам одновременно принять решение по одной жалобе
+		res := tx.Model(&report).Where("status = ?", model.ReportOpen).UpdateColumns(map[string]interface{}{
+			"status":      status,
+			"action":      req.Action,
+			"resolver_id": token.UserId,
+			"resolved_at": time.Now(),
+		})
+		if res.Error != nil {
+			return fmt.Errorf("Ошибка при сохранении решения: %v", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("Жалоба уже рассмотрена")
+		}
+
+		var err error
+		switch req.Action {
+		case model.ReportActionDismiss:
+			if report.Held {
+				err = releaseHeldContent(tx, &report)
+			}
+		case model.ReportActionHide, model.ReportActionDelete:
+			remove := req.Action == model.ReportActionDelete
+			if report.TargetType == model.ReportPost {
+				err = hideReportedPost(tx, report.TargetID, remove)
+			} else {
+				err = hideReportedComment(tx, report.TargetID, remove)
+			}
+		case model.ReportActionSuspend:
+			err = suspendUser(tx, report.TargetAuthorID, days)
+		}
+		if err != nil {
+			return err
+		}
+
+		return notifyReporters(tx, &report)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при рассмотрении жалобы: ", err)
+		return nil, err
+	}
+
+	return &model.ResolveReportResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Решение по жалобе принято",
+		},
+	}, nil
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// canEditPost проверяет, может ли владелец токена изменять пост
+func canEditPost(token *model.Token, post *model.Post) bool {
+	return post.AuthorID == token.UserId || token.Role == model.AdminRole
+}
+
+// tagNames возвращает имена тегов
+func tagNames(tags []model.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// savePostRevision сохраняет текущее состояние поста как новую ревизию.
+// restoredFrom - номер восстановленной ревизии или 0 для обычного сохранения.
+func savePostRevision(tx *gorm.DB, post *model.Post, editorID uint, restoredFrom uint) (*model.PostRevision, error) {
+	ensureRendered(tx, post)
+
+	var last uint
+	err := tx.Model(&model.PostRevision{}).Where("post_id = ?", post.ID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении номера ревизии: %v", err)
+	}
+
+	revision := model.PostRevision{
+		PostID:       post.ID,
+		Number:       last + 1,
+		EditorID:     editorID,
+		ByAdmin:      editorID != post.AuthorID,
+		RestoredFrom: restoredFrom,
+		Title:        post.Title,
+		SubTitle:     post.SubTitle,
+		Content:      post.Content,
+		ContentHTML:  post.ContentHTML,
+		Tags:         tagNames(post.Tags),
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, fmt.Errorf("Ошибка при сохранении ревизии: %v", err)
+	}
+
+	return &revision, nil
+}
+
+// ensureBaseRevision сохраняет исходное состояние поста, созданного до появления ревизий,
+// чтобы первая правка не потеряла старое содержимое
+func ensureBaseRevision(tx *gorm.DB, post *model.Post) error {
+	var count int64
+	err := tx.Model(&model.PostRevision{}).Where("post_id = ?", post.ID).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("Ошибка при проверке ревизий: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	revision, err := savePostRevision(tx, post, post.AuthorID, 0)
+	if err != nil {
+		return err
+	}
+
+	// Базовая ревизия датируется последним изменением поста, а не моментом миграции
+	return tx.Model(revision).UpdateColumn("created_at", post.UpdatedAt).Error
+}
+
+// revisionToJson преобразует ревизию в формат ответа
+func revisionToJson(revision *model.PostRevision, editorName string) model.PostRevisionJson {
+	return model.PostRevisionJson{
+		Number:       revision.Number,
+		EditorID:     revision.EditorID,
+		EditorName:   editorName,
+		ByAdmin:      revision.ByAdmin,
+		RestoredFrom: revision.RestoredFrom,
+		Title:        revision.Title,
+		SubTitle:     revision.SubTitle,
+		Tags:         revision.Tags,
+		Date:         revision.CreatedAt.Format("02.01.2006 15:04"),
+	}
+}
+
+// getEditablePost возвращает пост, если владелец токена может его изменять
+func getEditablePost(token *model.Token, postID uint) (*model.Post, error) {
+	var post model.Post
+	err := db.App.Preload("Tags").First(&post, postID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пост не найден")
+		}
+		return nil, err
+	}
+
+	if !canEditPost(token, &post) {
+		return nil, fmt.Errorf("У вас нет доступа к этому посту")
+	}
+
+	return &post, nil
+}
+
+// getRevision возвращает ревизию поста по номеру
+func getRevision(postID, number uint) (*model.PostRevision, error) {
+	var revision model.PostRevision
+	err := db.App.Where("post_id = ? AND number = ?", postID, number).First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Ревизия %d не найдена", number)
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// editorNames возвращает имена пользователей, сохранивших ревизии
+func editorNames(revisions []model.PostRevision) (map[uint]string, error) {
+	ids := make([]uint, 0, len(revisions))
+	for _, revision := range revisions {
+		ids = append(ids, revision.EditorID)
+	}
+
+	var users []model.User
+	if err := db.App.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+	return names, nil
+}
+
+// GetPostRevisions возвращает историю ревизий поста, от новых к старым
+func GetPostRevisions(jwtToken string, req model.GetPostRevisionsRequest) (*model.GetPostRevisionsResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := getEditablePost(token, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []model.PostRevision
+	err = db.App.Where("post_id = ?", post.ID).Order("number DESC").Find(&revisions).Error
+	if err != nil {
+		log.App.Error("Ошибка при получении ревизий поста: ", err)
+		return nil, err
+	}
+
+	names, err := editorNames(revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.PostRevisionJson, 0, len(revisions))
+	for i := range revisions {
+		result = append(result, revisionToJson(&revisions[i], names[revisions[i].EditorID]))
+	}
+
+	return &model.GetPostRevisionsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Ревизии получены",
+		},
+		Revisions: result,
+	}, nil
+}
+
+// GetRevisionDiff возвращает построчное сравнение двух ревизий поста
+func GetRevisionDiff(jwtToken string, req model.GetRevisionDiffRequest) (*model.GetRevisionDiffResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := getEditablePost(token, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := getRevision(post.ID, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getRevision(post.ID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := editorNames([]model.PostRevision{*from, *to})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetRevisionDiffResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Сравнение ревизий получено",
+		},
+		From:     revisionToJson(from, names[from.EditorID]),
+		To:       revisionToJson(to, names[to.EditorID]),
+		Title:    utils.DiffLines(from.Title, to.Title),
+		SubTitle: utils.DiffLines(from.SubTitle, to.SubTitle),
+		Content:  utils.DiffLines(from.Content, to.Content),
+		Tags:     utils.DiffSlices(from.Tags, to.Tags),
+	}, nil
+}
+
+// RestorePostRevision восстанавливает содержимое поста из старой ревизии.
+// Восстановление сохраняется как новая ревизия, история не переписывается.
+func RestorePostRevision(jwtToken string, req model.RestoreRevisionRequest) (*model.RestoreRevisionResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := getEditablePost(token, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := getRevision(post.ID, req.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored *model.PostRevision
+	err = withNotifications(func(tx *gorm.DB) error {
+		if err := ensureBaseRevision(tx, post); err != nil {
+			return err
+		}
+
+		post.Title = revision.Title
+		post.SubTitle = revision.SubTitle
+		post.Content = revision.Content
+
+		// Ревизия хранит готовый HTML, повторный рендеринг нужен только для ревизий без него
+		post.ContentHTML = revision.ContentHTML
+		if post.ContentHTML == "" {
+			if err := renderPost(tx, post); err != nil {
+				return err
+			}
+		} else {
+			summarizePost(post)
+		}
+
+		if err := updatePostFields(tx, post, post.Version); err != nil {
+			return err
+		}
+
+		if err := assignSlug(tx, post); err != nil {
+			return err
+		}
+
+		if err := replacePostTags(tx, post, revision.Tags); err != nil {
+			return err
+		}
+
+		restored, err = savePostRevision(tx, post, token.UserId, revision.Number)
+		if err != nil {
+			return err
+		}
+
+		if err := savePostMedia(tx, post.ID, post.Content, post.AuthorID); err != nil {
+			return err
+		}
+		if err := saveMentions(tx, post.ID, 0, post.Content, post.AuthorID); err != nil {
+			return err
+		}
+		return notifyMentions(tx, post.ID, 0, post.AuthorID)
+	})
+	if errors.Is(err, errVersionConflict) {
+		return nil, newPostConflictError(post.ID)
+	}
+	if err != nil {
+		log.App.Error("Ошибка при восстановлении ревизии: ", err)
+		return nil, err
+	}
+
+	return &model.RestoreRevisionResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: fmt.Sprintf("Ревизия %d восстановлена", revision.Number),
+		},
+		Number:  restored.Number,
+		Version: post.Version,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// SetUserRole назначает пользователя модератором или снимает с него эту роль. Доступно администраторам.
+// Роль администратора так назначить или снять нельзя. Открытые жалобы снятого модератора
+// возвращаются в общую очередь.
+func SetUserRole(jwtToken string, req model.SetUserRoleRequest) (*model.SetUserRoleResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Role != model.AdminRole {
+		return nil, fmt.Errorf("Назначать модераторов могут только администраторы")
+	}
+	if req.Role != model.ModeratorRole && req.Role != model.UserRole {
+		return nil, fmt.Errorf("Недопустимая роль: %s", req.Role)
+	}
+
+	var user model.User
+	if err := db.App.First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пользователь не найден")
+		}
+		return nil, err
+	}
+	if user.Role == model.AdminRole {
+		return nil, fmt.Errorf("Роль администратора нельзя изменить")
+	}
+
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).UpdateColumn("role", req.Role).Error; err != nil {
+			return err
+		}
+		if req.Role == model.UserRole {
+			return tx.Model(&model.Report{}).
+				Where("assignee_id = ? AND status = ?", user.ID, model.ReportOpen).
+				UpdateColumn("assignee_id", 0).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.App.Error("Ошибка при изменении роли пользователя: ", err)
+		return nil, err
+	}
+
+	message := "Пользователь назначен модератором"
+	if req.Role == model.UserRole {
+		message = "Роль модератора снята"
+	}
+	return &model.SetUserRoleResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: message,
+		},
+		Role: req.Role,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// postURL возвращает постоянную ссылку на пост по его slug
+func postURL(slug string) string {
+	if slug == "" {
+		return ""
+	}
+	return strings.TrimSuffix(config.File.APPURL, "/") + "/posts/" + slug
+}
+
+// slugMatchesBase проверяет, что slug получен из base, возможно с числовым суффиксом: base, base-2, base-3...
+func slugMatchesBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n > 1 && strconv.Itoa(n) == suffix
+}
+
+// assignSlug назначает посту slug по его заголовку. Если заголовок не изменил slug, текущий slug остается.
+// Прежние slug остаются в истории и продолжают вести на пост.
+func assignSlug(tx *gorm.DB, post *model.Post) error {
+	base := utils.Slugify(post.Title, "post")
+	if post.Slug != "" && slugMatchesBase(post.Slug, base) {
+		return nil
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		var existing model.PostSlug
+		err := tx.Where("slug = ?", candidate).First(&existing).Error
+		if err == nil && existing.PostID != post.ID {
+			// Slug занят другим постом, в том числе как прежний slug
+			continue
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Ошибка при проверке slug: %v", err)
+		}
+
+		// Slug свободен или уже принадлежал этому посту, например до прошлой смены заголовка
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Create(&model.PostSlug{Slug: candidate, PostID: post.ID}).Error; err != nil {
+				return fmt.Errorf("Ошибка при сохранении slug: %v", err)
+			}
+		}
+
+		err = tx.Unscoped().Model(&model.Post{}).Where("id = ?", post.ID).UpdateColumn("slug", candidate).Error
+		if err != nil {
+			return fmt.Errorf("Ошибка при сохранении slug: %v", err)
+		}
+		post.Slug = candidate
+		return nil
+	}
+}
+
+// BackfillPostSlugs назначает slug постам, созданным до появления slug, включая посты в корзине
+func BackfillPostSlugs() error {
+	var posts []model.Post
+	err := db.App.Unscoped().Where("slug IS NULL OR slug = ''").Order("id").Find(&posts).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		err := db.App.Transaction(func(tx *gorm.DB) error {
+			return assignSlug(tx, &posts[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(posts) > 0 {
+		log.App.Info("Назначены slug для постов: ", len(posts))
+	}
+	return nil
+}
+
+// GetPostBySlug возвращает пост по текущему или прежнему slug. Токен необязателен: без него пост
+// доступен только для чтения. Если slug устарел, вместо поста возвращается текущий slug для перенаправления.
+func GetPostBySlug(jwtToken, slug string) (*model.GetPostResponse, string, error) {
+	var postSlug model.PostSlug
+	err := db.App.Where("slug = ?", slug).First(&postSlug).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("Пост не найден")
+		}
+		return nil, "", err
+	}
+
+	var postDB model.Post
+	err = db.App.Preload("Tags").First(&postDB, postSlug.PostID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("Пост не найден")
+		}
+		return nil, "", err
+	}
+
+	if postDB.Slug != slug {
+		return nil, postDB.Slug, nil
+	}
+
+	canEdit := false
+	viewerID := uint(0)
+	var token *model.Token
+	if jwtToken != "" {
+		if token, err = ParseJWTToken(jwtToken); err == nil {
+			canEdit = canEditPost(token, &postDB)
+			viewerID = token.UserId
+		} else {
+			token = nil
+		}
+	}
+	if err := checkPostVisible(token, &postDB); err != nil {
+		return nil, "", err
+	}
+
+	post := postToJson(&postDB)
+	if err := fillReactions(&post, viewerID); err != nil {
+		return nil, "", err
+	}
+
+	return &model.GetPostResponse{
+		CanEdit: canEdit,
+		Status:  true,
+		Message: "Пост получен",
+		Post:    post,
+	}, "", nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// followedTagIDs возвращает ID тегов, на которые подписан пользователь
+func followedTagIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := db.App.Model(&model.TagSubscription{}).Where("user_id = ?", userID).Pluck("tag_id", &ids).Error
+	return ids, err
+}
+
+// boostFollowedTags переупорядочивает посты так, что посты с большим числом тегов из подписок пользователя
+// идут первыми. Порядок постов с одинаковым числом совпадений сохраняется.
+func boostFollowedTags(posts []model.Post, userID uint) error {
+	ids, err := followedTagIDs(userID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	followed := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		followed[id] = true
+	}
+	matches := func(post model.Post) int {
+		count := 0
+		for _, tag := range post.Tags {
+			if followed[tag.ID] {
+				count++
+			}
+		}
+		return count
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return matches(posts[i]) > matches(posts[j])
+	})
+	return nil
+}
+
+// FollowTag подписывает пользователя на тег. Повторная подписка не считается ошибкой.
+func FollowTag(jwtToken string, req model.FollowTagRequest) (*model.FollowTagResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag model.Tag
+	if err := db.App.First(&tag, req.TagID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Тег не найден")
+		}
+		return nil, err
+	}
+
+	subscription := model.TagSubscription{UserID: token.UserId, TagID: tag.ID}
+	if err := db.App.Clauses(clause.OnConflict{DoNothing: true}).Create(&subscription).Error; err != nil {
+		log.App.Error("Ошибка при подписке на тег: ", err)
+		return nil, fmt.Errorf("Ошибка при подписке на тег: %v", err)
+	}
+
+	return &model.FollowTagResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Вы подписались на тег",
+		},
+	}, nil
+}
+
+// UnfollowTag отписывает пользователя от тега
+func UnfollowTag(jwtToken string, req model.FollowTagRequest) (*model.FollowTagResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.App.Where("user_id = ? AND tag_id = ?", token.UserId, req.TagID).Delete(&model.TagSubscription{}).Error
+	if err != nil {
+		log.App.Error("Ошибка при отписке от тега: ", err)
+		return nil, fmt.Errorf("Ошибка при отписке от тега: %v", err)
+	}
+
+	return &model.FollowTagResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Вы отписались от тега",
+		},
+	}, nil
+}
+
+// GetFollowedTags возвращает теги, на которые подписан пользователь
+func GetFollowedTags(jwtToken string) (*model.GetFollowedTagsResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []model.TagJson
+	err = tagsWithCounts(db.App.DB).
+		Where("tags.id IN (SELECT tag_id FROM tag_subscriptions WHERE user_id = ?)", token.UserId).
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		log.App.Error("Ошибка при получении подписок на теги: ", err)
+		return nil, err
+	}
+
+	return &model.GetFollowedTagsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Подписки получены",
+		},
+		Tags: tags,
+	}, nil
+}
+
+// GetTagPosts возвращает страницу постов тега. Токен необязателен: он нужен для отметки лайков и подписки.
+func GetTagPosts(jwtToken string, req model.GetTagPostsRequest) (*model.GetTagPostsResponse, error) {
+	userID := optionalUserID(jwtToken)
+
+	tag, err := getTagJson(db.App.DB, req.TagID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Model(&model.Post{}).Where("posts.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)", tag.ID)
+	query = visiblePosts(query, userID)
+	posts, total, page, err := feedPage(query, req.Sort, req.Page, req.Limit)
+	if err != nil {
+		log.App.Error("Ошибка при получении постов тега: ", err)
+		return nil, err
+	}
+
+	feed, err := postsToFeed(posts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var following int64
+	if userID != 0 {
+		err := db.App.Model(&model.TagSubscription{}).Where("user_id = ? AND tag_id = ?", userID, tag.ID).Count(&following).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.GetTagPostsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Посты тега получены",
+		},
+		Tag:       tag,
+		Following: following > 0,
+		Posts:     feed,
+		Page:      page,
+		Total:     total,
+	}, nil
+}
+
+// GetFollowedTagsFeed возвращает страницу постов со всех тегов, на которые подписан пользователь
+func GetFollowedTagsFeed(jwtToken string, req model.GetFollowedTagsFeedRequest) (*model.GetFollowedTagsFeedResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Model(&model.Post{}).Where(`posts.id IN (SELECT post_id FROM post_tags
+		WHERE tag_id IN (SELECT tag_id FROM tag_subscriptions WHERE user_id = ?))`, token.UserId)
+	query = visiblePosts(query, token.UserId)
+	posts, total, page, err := feedPage(query, req.Sort, req.Page, req.Limit)
+	if err != nil {
+		log.App.Error("Ошибка при получении ленты подписок: ", err)
+		return nil, err
+	}
+
+	feed, err := postsToFeed(posts, token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GetFollowedTagsFeedResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Лента подписок получена",
+		},
+		Posts: feed,
+		Page:  page,
+		Total: total,
+	}, nil
+}
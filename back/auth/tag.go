@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/markdown"
+	"app/model"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// normalizeTag приводит имя тега к единому виду: без разметки, в нижнем регистре, с одиночными пробелами
+func normalizeTag(name string) string {
+	name = markdown.App.PlainText(name)
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// normalizeTags нормализует имена тегов, отбрасывая пустые и повторяющиеся
+func normalizeTags(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name == "" || slices.Contains(result, name) {
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// validateTagName проверяет длину нормализованного имени тега
+func validateTagName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Имя тега не может быть пустым")
+	}
+	if maxLength := config.File.TagConfig.MaxLength; utf8.RuneCountInString(name) > maxLength {
+		return fmt.Errorf("Тег %q длиннее %d символов", name, maxLength)
+	}
+	return nil
+}
+
+// validatePostTags проверяет нормализованные теги поста. Зарезервированные теги может ставить только
+// администратор, но автор может сохранить пост с уже поставленным администратором тегом.
+func validatePostTags(token *model.Token, names []string, current []model.Tag) error {
+	conf := config.File.TagConfig
+	if len(names) > conf.MaxPerPost {
+		return fmt.Errorf("У поста может быть не больше %d тегов", conf.MaxPerPost)
+	}
+
+	currentNames := tagNames(current)
+	for _, name := range names {
+		if err := validateTagName(name); err != nil {
+			return err
+		}
+		if token.Role == model.AdminRole || slices.Contains(currentNames, name) {
+			continue
+		}
+		if slices.ContainsFunc(conf.Reserved, func(reserved string) bool { return normalizeTag(reserved) == name }) {
+			return fmt.Errorf("Тег %q может ставить только администратор", name)
+		}
+	}
+	return nil
+}
+
+// findOrCreateTags возвращает теги с указанными именами, создавая отсутствующие.
+// Имена нормализуются, поэтому "Go" и " go " дают один и тот же тег.
+func findOrCreateTags(tx *gorm.DB, names []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	for _, tagName := range normalizeTags(names) {
+		var tag model.Tag
+
+		// Теги, созданные до нормализации, могут быть в другом регистре
+		err := tx.Where("LOWER(name) = ?", tagName).Order("id").First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Если тег не существует, создаём его
+			tag = model.Tag{Name: tagName}
+			if err := tx.Create(&tag).Error; err != nil {
+				return nil, fmt.Errorf("Ошибка при создании тега: %v", err)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("Ошибка при проверке существования тега: %v", err)
+		}
+
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// replacePostTags заменяет теги поста на теги с указанными именами
+func replacePostTags(tx *gorm.DB, post *model.Post, names []string) error {
+	newTags, err := findOrCreateTags(tx, names)
+	if err != nil {
+		return err
+	}
+
+	// Удаляем старые связи
+	if err := tx.Model(post).Association("Tags").Clear(); err != nil {
+		return fmt.Errorf("Ошибка при удалении старых тегов: %v", err)
+	}
+
+	// Привязываем новые теги
+	if len(newTags) > 0 {
+		if err := tx.Model(post).Association("Tags").Append(newTags); err != nil {
+			return fmt.Errorf("Ошибка при добавлении новых тегов: %v", err)
+		}
+	}
+
+	post.Tags = newTags
+	return nil
+}
+
+// tagsWithCounts возвращает запрос тегов с количеством неудаленных постов
+func tagsWithCounts(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&model.Tag{}).
+		Select("tags.id, tags.name, tags.description, COUNT(posts.id) AS posts").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL").
+		Group("tags.id, tags.name, tags.description")
+}
+
+// getTagJson возвращает тег с количеством постов
+func getTagJson(tx *gorm.DB, id uint) (model.TagJson, error) {
+	var tag model.TagJson
+	err := tagsWithCounts(tx).Where("tags.id = ?", id).Scan(&tag).Error
+	if err == nil && tag.ID == 0 {
+		err = fmt.Errorf("Тег не найден")
+	}
+	return tag, err
+}
+
+// GetTags возвращает все теги с количеством постов, начиная с самых популярных
+func GetTags() (*model.GetTagsResponse, error) {
+	var tags []model.TagJson
+	if err := tagsWithCounts(db.App.DB).Order("posts DESC, tags.name").Scan(&tags).Error; err != nil {
+		log.App.Error("Ошибка при получении тегов: ", err)
+		return nil, err
+	}
+
+	return &model.GetTagsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Теги получены",
+		},
+		Tags: tags,
+	}, nil
+}
+
+// requireAdmin проверяет, что владелец токена - администратор
+func requireAdmin(jwtToken string) (*model.Token, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.Role != model.AdminRole {
+		return nil, fmt.Errorf("Действие доступно только администратору")
+	}
+	return token, nil
+}
+
+// UpdateTag переименовывает тег и меняет его описание. Если тег с новым именем уже есть, теги нужно объединить.
+func UpdateTag(jwtToken string, req model.UpdateTagRequest) (*model.UpdateTagResponse, error) {
+	if _, err := requireAdmin(jwtToken); err != nil {
+		return nil, err
+	}
+
+	name := normalizeTag(req.Name)
+	if err := validateTagName(name); err != nil {
+		return nil, err
+	}
+
+	var tag model.Tag
+	if err := db.App.First(&tag, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Тег не найден")
+		}
+		return nil, err
+	}
+
+	var duplicate int64
+	err := db.App.Model(&model.Tag{}).Where("LOWER(name) = ? AND id <> ?", name, tag.ID).Count(&duplicate).Error
+	if err != nil {
+		return nil, err
+	}
+	if duplicate > 0 {
+		return nil, fmt.Errorf("Тег %q уже существует. Объедините теги вместо переименования", name)
+	}
+
+	err = db.App.Model(&tag).Updates(map[string]interface{}{
+		"name":        name,
+		"description": strings.TrimSpace(markdown.App.PlainText(req.Description)),
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при сохранении тега: %v", err)
+	}
+
+	tagJson, err := getTagJson(db.App.DB, tag.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdateTagResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Тег обновлен",
+		},
+		Tag: tagJson,
+	}, nil
+}
+
+// MergeTags переносит посты и подписчиков тегов FromIDs в тег ToID и удаляет теги FromIDs
+func MergeTags(jwtToken string, req model.MergeTagsRequest) (*model.MergeTagsResponse, error) {
+	if _, err := requireAdmin(jwtToken); err != nil {
+		return nil, err
+	}
+
+	fromIDs := slices.Clone(req.FromIDs)
+	slices.Sort(fromIDs)
+	fromIDs = slices.DeleteFunc(slices.Compact(fromIDs), func(id uint) bool { return id == req.ToID })
+	if len(fromIDs) == 0 {
+		return nil, fmt.Errorf("Не указаны теги для объединения")
+	}
+
+	var count int64
+	if err := db.App.Model(&model.Tag{}).Where("id IN ? OR id = ?", fromIDs, req.ToID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if int(count) != len(fromIDs)+1 {
+		return nil, fmt.Errorf("Тег не найден")
+	}
+
+	err := db.App.Transaction(func(tx *gorm.DB) error {
+		// Посты, у которых уже есть целевой тег, не получают его второй раз
+		err := tx.Exec(`INSERT INTO post_tags (post_id, tag_id)
+			SELECT DISTINCT post_id, ? FROM post_tags
+			WHERE tag_id IN ? AND post_id NOT IN (SELECT post_id FROM post_tags WHERE tag_id = ?)`,
+			req.ToID, fromIDs, req.ToID).Error
+		if err != nil {
+			return fmt.Errorf("Ошибка при переносе постов: %v", err)
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id IN ?", fromIDs).Error; err != nil {
+			return fmt.Errorf("Ошибка при переносе постов: %v", err)
+		}
+
+		// Подписчики объединяемых тегов становятся подписчиками целевого тега
+		err = tx.Exec(`INSERT INTO tag_subscriptions (user_id, tag_id, created_at)
+			SELECT user_id, ?, MIN(created_at) FROM tag_subscriptions
+			WHERE tag_id IN ? AND user_id NOT IN (SELECT user_id FROM tag_subscriptions WHERE tag_id = ?)
+			GROUP BY user_id`,
+			req.ToID, fromIDs, req.ToID).Error
+		if err != nil {
+			return fmt.Errorf("Ошибка при переносе подписок: %v", err)
+		}
+		if err := tx.Where("tag_id IN ?", fromIDs).Delete(&model.TagSubscription{}).Error; err != nil {
+			return fmt.Errorf("Ошибка при переносе подписок: %v", err)
+		}
+
+		// Удаляем полностью, чтобы имена объединенных тегов можно было использовать снова
+		return tx.Unscoped().Where("id IN ?", fromIDs).Delete(&model.Tag{}).Error
+	})
+	if err != nil {
+		log.App.Error("Ошибка при объединении тегов: ", err)
+		return nil, err
+	}
+
+	tagJson, err := getTagJson(db.App.DB, req.ToID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MergeTagsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Теги объединены",
+		},
+		Tag: tagJson,
+	}, nil
+}
+
+// DeleteUnusedTags удаляет теги, которые не стоят ни у одного поста и на которые никто не подписан
+func DeleteUnusedTags(jwtToken string) (*model.DeleteUnusedTagsResponse, error) {
+	if _, err := requireAdmin(jwtToken); err != nil {
+		return nil, err
+	}
+
+	res := db.App.Unscoped().Where("id NOT IN (SELECT tag_id FROM post_tags) AND id NOT IN (SELECT tag_id FROM tag_subscriptions)").Delete(&model.Tag{})
+	if res.Error != nil {
+		log.App.Error("Ошибка при удалении неиспользуемых тегов: ", res.Error)
+		return nil, res.Error
+	}
+
+	return &model.DeleteUnusedTagsResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Неиспользуемые теги удалены",
+		},
+		Deleted: int(res.RowsAffected),
+	}, nil
+}
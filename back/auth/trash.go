@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// trashRetention возвращает срок хранения удаленных постов
+func trashRetention() time.Duration {
+	return time.Duration(config.File.TrashConfig.RetentionDays) * 24 * time.Hour
+}
+
+// GetTrash возвращает удаленные посты пользователя. Администратор видит все удаленные посты.
+func GetTrash(jwtToken string) (*model.GetTrashResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.App.Unscoped().Where("deleted_at IS NOT NULL")
+	if token.Role != model.AdminRole {
+		query = query.Where("author_id = ?", token.UserId)
+	}
+
+	var posts []model.Post
+	if err := query.Order("deleted_at DESC").Find(&posts).Error; err != nil {
+		log.App.Error("Ошибка при получении корзины: ", err)
+		return nil, err
+	}
+
+	authorIDs := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.AuthorID)
+	}
+	var authors []model.User
+	if err := db.App.Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+		return nil, err
+	}
+	authorNames := make(map[uint]string, len(authors))
+	for _, author := range authors {
+		authorNames[author.ID] = author.Name
+	}
+
+	result := make([]model.TrashPost, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, model.TrashPost{
+			ID:         post.ID,
+			Title:      post.Title,
+			SubTitle:   post.SubTitle,
+			AuthorId:   post.AuthorID,
+			AuthorName: authorNames[post.AuthorID],
+			DeletedAt:  post.DeletedAt.Time.Format("02.01.2006 15:04"),
+			PurgeAt:    post.DeletedAt.Time.Add(trashRetention()).Format("02.01.2006"),
+		})
+	}
+
+	return &model.GetTrashResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Корзина получена",
+		},
+		Posts: result,
+	}, nil
+}
+
+// RestorePost восстанавливает пост из корзины вместе с тегами и лайками. Теги поста сохраняются в корзине,
+// поэтому после восстановления учитываются объединения и переименования тегов.
+func RestorePost(jwtToken string, req model.RestorePostRequest) (*model.RestorePostResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var post model.Post
+	err = db.App.Unscoped().Where("deleted_at IS NOT NULL").First(&post, req.ID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Пост не найден в корзине")
+		}
+		return nil, err
+	}
+
+	if !canEditPost(token, &post) {
+		return nil, fmt.Errorf("У вас нет доступа к этому посту")
+	}
+
+	// Посты, удаленные до сохранения тегов в корзине, получают теги из последней ревизии
+	tagCount := db.App.Model(&post).Association("Tags").Count()
+	var revision model.PostRevision
+	if tagCount == 0 {
+		err = db.App.Where("post_id = ?", post.ID).Order("number DESC").First(&revision).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	deletedAt := post.DeletedAt.Time
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&post).Update("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("Ошибка при восстановлении поста: %v", err)
+		}
+		post.DeletedAt = gorm.DeletedAt{}
+
+		// Лайки, снятые до удаления поста, остаются снятыми
+		err := tx.Unscoped().Model(&model.Like{}).
+			Where("post_id = ? AND deleted_at >= ?", post.ID, deletedAt).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return fmt.Errorf("Ошибка при восстановлении лайков: %v", err)
+		}
+
+		if len(revision.Tags) == 0 {
+			return nil
+		}
+		return replacePostTags(tx, &post, revision.Tags)
+	})
+	if err != nil {
+		log.App.Error("Ошибка при восстановлении поста из корзины: ", err)
+		return nil, err
+	}
+
+	return &model.RestorePostResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Пост восстановлен",
+		},
+	}, nil
+}
+
+// PurgeDeletedPosts окончательно удаляет посты, пролежавшие в корзине дольше срока хранения,
+// вместе с их связями с тегами, лайками, ревизиями, slug, комментариями, упоминаниями, уведомлениями и жалобами
+// на посты и их комментарии. Файлы, на которые не ссылаются
+// другие посты, тоже удаляются. Возвращает количество удаленных постов.
+// Закладки на такие посты остаются и показываются как удаленные.
+func PurgeDeletedPosts() (int, error) {
+	before := time.Now().Add(-trashRetention())
+
+	var ids []uint
+	err := db.App.Unscoped().Model(&model.Post{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Файлы, на которые ссылались только удаляемые посты, удаляются вместе с ними
+	var orphanMedia []string
+	err = db.App.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		keys, err := releasePostMedia(tx, ids)
+		if err != nil {
+			return err
+		}
+		orphanMedia = keys
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&model.Like{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&model.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", ids).Delete(&model.PostSlug{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&model.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", ids).Delete(&model.Mention{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&model.Notification{}).Error; err != nil {
+			return err
+		}
+
+		// Жалобы на удаляемые посты и комментарии к ним ссылались бы на несуществующие объекты
+		reports := tx.Unscoped().Model(&model.Report{}).Select("id").Where("post_id IN ?", ids)
+		if err := tx.Where("report_id IN (?)", reports).Delete(&model.ReportEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&model.Report{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Post{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	removeMediaFiles(orphanMedia)
+
+	return len(ids), nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	usernameMinLength = 3
+	usernameMaxLength = 30
+)
+
+// usernamePattern допустимое имя пользователя: латинские буквы в нижнем регистре, цифры, дефисы и подчеркивания,
+// в начале и в конце - буква или цифра
+var usernamePattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9_-]*[a-z0-9])?$`)
+
+// profileURL возвращает ссылку на профиль пользователя. Ссылка не зависит от имени пользователя,
+// поэтому упоминания в сохраненных постах не устаревают при смене имени.
+func profileURL(userID uint) string {
+	return fmt.Sprintf("%s/profile?id=%d", strings.TrimSuffix(config.File.APPURL, "/"), userID)
+}
+
+// validateUsername проверяет имя пользователя и приводит его к нижнему регистру
+func validateUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if len(username) < usernameMinLength || len(username) > usernameMaxLength || !usernamePattern.MatchString(username) {
+		return "", fmt.Errorf("Имя пользователя должно состоять из %d-%d латинских букв, цифр, дефисов и подчеркиваний "+
+			"и начинаться и заканчиваться буквой или цифрой", usernameMinLength, usernameMaxLength)
+	}
+	return username, nil
+}
+
+// assignUsername назначает пользователю свободное имя по его отображаемому имени: name, name-2, name-3...
+func assignUsername(tx *gorm.DB, user *model.User) error {
+	// Место для числового суффикса
+	base := utils.Slugify(user.Name, "user")
+	if len(base) > usernameMaxLength-4 {
+		base = strings.TrimRight(base[:usernameMaxLength-4], "-")
+	}
+	if len(base) < usernameMinLength {
+		base = "user-" + base
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		var taken int64
+		err := tx.Unscoped().Model(&model.User{}).Where("username = ? AND id <> ?", candidate, user.ID).Count(&taken).Error
+		if err != nil {
+			return fmt.Errorf("Ошибка при проверке имени пользователя: %v", err)
+		}
+		if taken > 0 {
+			continue
+		}
+
+		if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", user.ID).UpdateColumn("username", candidate).Error; err != nil {
+			return fmt.Errorf("Ошибка при сохранении имени пользователя: %v", err)
+		}
+		user.Username = candidate
+		return nil
+	}
+}
+
+// BackfillUsernames назначает имена пользователям, зарегистрированным до появления имен
+func BackfillUsernames() error {
+	var users []model.User
+	err := db.App.Unscoped().Where("username IS NULL OR username = ''").Order("id").Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if err := assignUsername(db.App.DB, &users[i]); err != nil {
+			return err
+		}
+	}
+
+	if len(users) > 0 {
+		log.App.Info("Назначены имена пользователей: ", len(users))
+	}
+	return nil
+}
+
+// SetUsername меняет имя пользователя, по которому его упоминают в постах
+func SetUsername(jwtToken string, req model.SetUsernameRequest) (*model.SetUsernameResponse, error) {
+	token, err := ParseJWTToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := validateUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	var taken int64
+	err = db.App.Unscoped().Model(&model.User{}).Where("username = ? AND id <> ?", username, token.UserId).Count(&taken).Error
+	if err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		return nil, fmt.Errorf("Имя пользователя занято")
+	}
+
+	res := db.App.Model(&model.User{}).Where("id = ?", token.UserId).UpdateColumn("username", username)
+	if res.Error != nil {
+		log.App.Error("Ошибка при смене имени пользователя: ", res.Error)
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("Пользователь не найден")
+	}
+
+	return &model.SetUsernameResponse{
+		Response: model.Response{
+			Status:  true,
+			Message: "Имя пользователя изменено",
+		},
+		Username: username,
+	}, nil
+}
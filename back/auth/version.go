@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"app/db"
+	"app/model"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// errVersionConflict возвращается, если пост в БД уже изменен другим сохранением
+var errVersionConflict = errors.New("version conflict")
+
+// PostConflictError возвращается при попытке сохранить устаревшую версию поста
+type PostConflictError struct {
+	Current model.PostJson // Актуальная версия поста на сервере
+}
+
+func (e *PostConflictError) Error() string {
+	return fmt.Sprintf("Пост был изменен другим сохранением (актуальная версия %d). Обновите пост и повторите изменения", e.Current.Version)
+}
+
+// PostETag возвращает ETag для версии поста
+func PostETag(postID, version uint) string {
+	return fmt.Sprintf(`"%d-%d"`, postID, version)
+}
+
+// MatchesETag проверяет, совпадает ли etag с одним из тегов заголовка If-None-Match. Теги сравниваются
+// по слабому правилу, то есть без учета префикса W/, а "*" совпадает с любым тегом.
+func MatchesETag(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePostETag извлекает версию поста из заголовка If-Match.
+// Для "*" возвращает 0, то есть проверка версии не выполняется.
+func ParsePostETag(header string, postID uint) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+
+	id, version, ok := strings.Cut(strings.Trim(header, `"`), "-")
+	if !ok {
+		return 0, fmt.Errorf("Некорректный заголовок If-Match")
+	}
+	if id != strconv.FormatUint(uint64(postID), 10) {
+		return 0, fmt.Errorf("Заголовок If-Match относится к другому посту")
+	}
+
+	parsed, err := strconv.ParseUint(version, 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, fmt.Errorf("Некорректный заголовок If-Match")
+	}
+	return uint(parsed), nil
+}
+
+// updatePostFields сохраняет заголовок, подзаголовок и содержание поста, только если версия поста в БД
+// равна expectedVersion. Версия увеличивается в том же запросе, поэтому два параллельных сохранения
+// одной версии не перезапишут друг друга.
+func updatePostFields(tx *gorm.DB, post *model.Post, expectedVersion uint) error {
+	res := tx.Model(&model.Post{}).
+		Where("id = ? AND version = ?", post.ID, expectedVersion).
+		Updates(map[string]interface{}{
+			"title":        post.Title,
+			"sub_title":    post.SubTitle,
+			"content":      post.Content,
+			"content_html": post.ContentHTML,
+			"excerpt":      post.Excerpt,
+			"word_count":   post.WordCount,
+			"reading_time": post.ReadingTime,
+			"toc":          post.TOC,
+			"fingerprint":  post.Fingerprint,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("Ошибка при сохранении поста: %v", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errVersionConflict
+	}
+
+	post.Version = expectedVersion + 1
+	return nil
+}
+
+// newPostConflictError загружает актуальную версию поста для ответа о конфликте
+func newPostConflictError(postID uint) error {
+	var post model.Post
+	if err := db.App.Preload("Tags").First(&post, postID).Error; err != nil {
+		return err
+	}
+	return &PostConflictError{Current: postToJson(&post)}
+}
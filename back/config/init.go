@@ -7,6 +7,18 @@ type Config struct {
 	model.DataBaseConfig
 	model.SmtpConfig
 	model.AuthConfig
+	model.TrashConfig
+	model.PostConfig
+	model.TagConfig
+	model.CommentConfig
+	model.ReactionConfig
+	model.NotificationConfig
+	model.LiveConfig
+	model.ReportConfig
+	model.FilterConfig
+	model.PowConfig
+	model.RateLimitConfig
+	model.MediaConfig
 }
 
 var File *Config = &Config{}
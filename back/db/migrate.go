@@ -3,21 +3,97 @@ package db
 import (
 	"app/log"
 	"app/model"
+
+	"gorm.io/gorm"
 )
 
 // Migrate выполняет миграции базы данных
 func (db *DataBase) Migrate() error {
 
+	if err := db.prepareLikes(); err != nil {
+		log.App.Error("Подготовка лайков к миграции не удалась:", err)
+		return err
+	}
+
 	err := db.AutoMigrate(
 		&model.User{},
 		&model.Post{},
 		&model.Tag{},
 		&model.Like{},
+		&model.PostRevision{},
+		&model.PostSlug{},
+		&model.TagSubscription{},
+		&model.Comment{},
+		&model.Notification{},
+		&model.CommentBan{},
+		&model.ReadingList{},
+		&model.Bookmark{},
+		&model.Follow{},
+		&model.Mention{},
+		&model.Block{},
+		&model.Mute{},
+		&model.Report{},
+		&model.ReportEntry{},
+		&model.FilterRules{},
+		&model.Media{},
+		&model.MediaVariant{},
+		&model.PostMedia{},
 	)
 	if err != nil {
 		log.App.Error("Auto-migration failed:", err)
 		return err
 	}
 
+	if err := db.markHeldComments(); err != nil {
+		log.App.Error("Перенос задержанных комментариев не удался:", err)
+		return err
+	}
+
+	// Лента подписок выбирает посты нескольких авторов по дате
+	if !db.Migrator().HasIndex(&model.Post{}, "idx_posts_author_created") {
+		if err := db.Exec("CREATE INDEX idx_posts_author_created ON posts (author_id, created_at DESC, id DESC)").Error; err != nil {
+			log.App.Error("Создание индекса постов не удалось:", err)
+			return err
+		}
+	}
+
 	return nil
 }
+
+// markHeldComments переводит в отдельный статус комментарии, задержанные фильтром до его появления.
+// Раньше они ожидали одобрения вместе с обычными, и их мог одобрить автор поста.
+func (db *DataBase) markHeldComments() error {
+	held := db.Model(&model.Report{}).Select("target_id").
+		Where("target_type = ? AND held = ? AND status = ?", model.ReportComment, true, model.ReportOpen)
+	return db.Model(&model.Comment{}).Where("status = ? AND id IN (?)", model.CommentPending, held).
+		UpdateColumn("status", model.CommentHeld).Error
+}
+
+// prepareLikes готовит таблицу лайков к уникальному индексу (user_id, post_id, kind): удаляет снятые лайки
+// живых постов и повторные лайки, которые раньше появлялись при одновременных запросах.
+// Лайки постов из корзины, скрытые вместе с постом, сохраняются.
+func (db *DataBase) prepareLikes() error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.Like{}) || migrator.HasIndex(&model.Like{}, "idx_like_user_post_kind") {
+		return nil
+	}
+	if !migrator.HasColumn(&model.Like{}, "Kind") {
+		if err := migrator.AddColumn(&model.Like{}, "Kind"); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM likes WHERE deleted_at IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM posts WHERE posts.id = likes.post_id
+			AND posts.deleted_at IS NOT NULL AND likes.deleted_at >= posts.deleted_at)`).Error
+		if err != nil {
+			return err
+		}
+
+		// Из повторных лайков остается последний
+		return tx.Exec(`DELETE FROM likes WHERE EXISTS (
+			SELECT 1 FROM likes newer WHERE newer.user_id = likes.user_id AND newer.post_id = likes.post_id
+			AND newer.kind = likes.kind AND newer.id > likes.id)`).Error
+	})
+}
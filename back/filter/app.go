@@ -0,0 +1,172 @@
+// В данном пакете реализуется проверка нового содержимого на спам и злоупотребления.
+// Проверки подключаются к конвейеру и выполняются по очереди; содержимое, не прошедшее
+// хотя бы одну проверку, задерживается до решения модератора.
+package filter
+
+import (
+	"app/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Таблицы проверяемого содержимого
+const (
+	TablePosts    = "posts"
+	TableComments = "comments"
+)
+
+// linkPattern ссылка в тексте: адрес с протоколом или начинающийся с www.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]]+`)
+
+// Content проверяемый пост или комментарий
+type Content struct {
+	Table       string    // Таблица содержимого: posts или comments
+	ID          uint      // ID изменяемого содержимого, 0 - новое содержимое
+	AuthorID    uint      // Автор содержимого
+	AuthorSince time.Time // Время регистрации автора
+	Text        string    // Исходный текст
+
+	words []string
+	links int
+}
+
+// NewContent подготавливает текст автора author к проверке
+func NewContent(table string, id uint, author *model.User, text string) *Content {
+	return &Content{
+		Table:       table,
+		ID:          id,
+		AuthorID:    author.ID,
+		AuthorSince: author.CreatedAt,
+		Text:        text,
+		words:       splitWords(text),
+		links:       len(linkPattern.FindAllString(text, -1)),
+	}
+}
+
+// splitWords разбивает текст на слова в нижнем регистре. Знаки препинания и разметка отбрасываются.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Words возвращает слова текста в нижнем регистре
+func (c *Content) Words() []string {
+	return c.words
+}
+
+// Normalized возвращает текст без разметки, знаков препинания и различий в регистре и пробелах
+func (c *Content) Normalized() string {
+	return strings.Join(c.words, " ")
+}
+
+// Links возвращает количество ссылок в тексте
+func (c *Content) Links() int {
+	return c.links
+}
+
+// Fingerprint возвращает отпечаток текста. Тексты, отличающиеся только разметкой, регистром
+// и знаками препинания, имеют одинаковый отпечаток.
+func (c *Content) Fingerprint() string {
+	sum := sha256.Sum256([]byte(c.Normalized()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Rules правила фильтра, подготовленные к проверкам
+type Rules struct {
+	model.FilterRules
+	phrases  []string         // Запрещенные слова и фразы в том же виде, что и Content.Normalized
+	patterns []*regexp.Regexp // Запрещенные регулярные выражения
+}
+
+// Compile подготавливает правила к проверкам. Возвращает ошибку, если регулярное выражение недопустимо.
+func Compile(rules model.FilterRules) (*Rules, error) {
+	compiled := &Rules{FilterRules: rules}
+	for _, word := range rules.BannedWords {
+		if phrase := strings.Join(splitWords(word), " "); phrase != "" {
+			compiled.phrases = append(compiled.phrases, phrase)
+		}
+	}
+	for _, pattern := range rules.BannedPatterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Недопустимое регулярное выражение %q: %v", pattern, err)
+		}
+		compiled.patterns = append(compiled.patterns, re)
+	}
+	return compiled, nil
+}
+
+// Check проверка содержимого. Возвращает причину задержки содержимого или пустую строку, если проверка пройдена.
+type Check interface {
+	Name() string
+	Check(tx *gorm.DB, content *Content, rules *Rules) (string, error)
+}
+
+// Pipeline конвейер проверок. Правила можно заменить во время работы.
+type Pipeline struct {
+	mu     sync.RWMutex
+	checks []Check
+	rules  *Rules
+}
+
+// NewPipeline создает конвейер с правилами rules и проверками checks
+func NewPipeline(rules *Rules, checks ...Check) *Pipeline {
+	return &Pipeline{
+		checks: checks,
+		rules:  rules,
+	}
+}
+
+// Register добавляет проверку в конец конвейера
+func (p *Pipeline) Register(check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, check)
+}
+
+// SetRules заменяет правила фильтра. Следующие проверки выполняются уже по новым правилам.
+func (p *Pipeline) SetRules(rules *Rules) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = rules
+}
+
+// Rules возвращает действующие правила фильтра
+func (p *Pipeline) Rules() model.FilterRules {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rules.FilterRules
+}
+
+// Run проверяет содержимое всеми проверками конвейера и возвращает причины задержки.
+// Пустой результат означает, что содержимое можно публиковать. Незапущенный фильтр пропускает все.
+func (p *Pipeline) Run(tx *gorm.DB, content *Content) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	p.mu.RLock()
+	checks, rules := p.checks, p.rules
+	p.mu.RUnlock()
+
+	var reasons []string
+	for _, check := range checks {
+		reason, err := check.Check(tx, content, rules)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при проверке %s: %v", check.Name(), err)
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons, nil
+}
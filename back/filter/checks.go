@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// DefaultChecks возвращает встроенные проверки в порядке выполнения
+func DefaultChecks() []Check {
+	return []Check{
+		LinkCheck{},
+		BannedCheck{},
+		DuplicateCheck{},
+		RateCheck{},
+		ProbationCheck{},
+	}
+}
+
+// LinkCheck задерживает текст со слишком большим количеством ссылок или слишком частыми ссылками.
+// Плотность не проверяется для текста с одной ссылкой, иначе короткий комментарий со ссылкой не пройдет проверку.
+type LinkCheck struct{}
+
+func (LinkCheck) Name() string {
+	return "links"
+}
+
+func (LinkCheck) Check(tx *gorm.DB, content *Content, rules *Rules) (string, error) {
+	links := content.Links()
+	if rules.MaxLinks > 0 && links > rules.MaxLinks {
+		return fmt.Sprintf("Слишком много ссылок: %d", links), nil
+	}
+
+	words := len(content.Words())
+	if rules.MaxLinkDensity > 0 && links > 1 && links*100 > rules.MaxLinkDensity*words {
+		return fmt.Sprintf("Слишком много ссылок для текста такой длины: %d на %d слов", links, words), nil
+	}
+	return "", nil
+}
+
+// BannedCheck задерживает текст с запрещенными словами, фразами и совпадениями с регулярными выражениями.
+// Слова и фразы ищутся целиком без учета регистра и знаков препинания.
+type BannedCheck struct{}
+
+func (BannedCheck) Name() string {
+	return "banned"
+}
+
+func (BannedCheck) Check(tx *gorm.DB, content *Content, rules *Rules) (string, error) {
+	text := " " + content.Normalized() + " "
+	for _, phrase := range rules.phrases {
+		if strings.Contains(text, " "+phrase+" ") {
+			return fmt.Sprintf("Запрещенное слово: %s", phrase), nil
+		}
+	}
+
+	for _, pattern := range rules.patterns {
+		if pattern.MatchString(content.Text) {
+			return fmt.Sprintf("Совпадение с запрещенным шаблоном: %s", pattern.String()), nil
+		}
+	}
+	return "", nil
+}
+
+// DuplicateCheck задерживает текст, который недавно уже публиковался в той же таблице.
+// Короткие тексты не проверяются: одинаковые короткие комментарии обычно не спам.
+type DuplicateCheck struct{}
+
+func (DuplicateCheck) Name() string {
+	return "duplicate"
+}
+
+func (DuplicateCheck) Check(tx *gorm.DB, content *Content, rules *Rules) (string, error) {
+	if rules.DuplicateWindow <= 0 || utf8.RuneCountInString(content.Normalized()) < rules.DuplicateMinLength {
+		return "", nil
+	}
+
+	since := time.Now().Add(-time.Duration(rules.DuplicateWindow) * time.Hour)
+	var count int64
+	err := tx.Table(content.Table).
+		Where("fingerprint = ? AND id <> ? AND updated_at >= ?", content.Fingerprint(), content.ID, since).
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "Такой же текст недавно уже публиковался", nil
+	}
+	return "", nil
+}
+
+// RateCheck задерживает новое содержимое пользователя, который публикует слишком часто.
+// Удаленное содержимое тоже учитывается, изменения не проверяются.
+type RateCheck struct{}
+
+func (RateCheck) Name() string {
+	return "rate"
+}
+
+func (RateCheck) Check(tx *gorm.DB, content *Content, rules *Rules) (string, error) {
+	limit := rules.PostsPerHour
+	if content.Table == TableComments {
+		limit = rules.CommentsPerHour
+	}
+	if content.ID != 0 || limit <= 0 {
+		return "", nil
+	}
+
+	var count int64
+	err := tx.Table(content.Table).
+		Where("author_id = ? AND created_at >= ?", content.AuthorID, time.Now().Add(-time.Hour)).
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+	if count >= int64(limit) {
+		return fmt.Sprintf("Слишком много публикаций за час: %d", count+1), nil
+	}
+	return "", nil
+}
+
+// ProbationCheck задерживает текст со ссылками от аккаунта на испытательном сроке
+type ProbationCheck struct{}
+
+func (ProbationCheck) Name() string {
+	return "probation"
+}
+
+func (ProbationCheck) Check(tx *gorm.DB, content *Content, rules *Rules) (string, error) {
+	if rules.ProbationDays <= 0 || time.Since(content.AuthorSince) >= time.Duration(rules.ProbationDays)*24*time.Hour {
+		return "", nil
+	}
+	if content.Links() > rules.ProbationMaxLinks {
+		return "Ссылки от новых аккаунтов проверяются модератором", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"app/config"
+	"app/db"
+	"app/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var App *Pipeline
+
+func Init() error {
+	rules, err := LoadRules(db.App.DB)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := Compile(rules)
+	if err != nil {
+		return err
+	}
+	App = NewPipeline(compiled, DefaultChecks()...)
+	return nil
+}
+
+// LoadRules возвращает правила, сохраненные администратором, или правила из конфигурации
+func LoadRules(tx *gorm.DB) (model.FilterRules, error) {
+	var rules model.FilterRules
+	err := tx.First(&rules).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultRules(), nil
+	}
+	return rules, err
+}
+
+// DefaultRules возвращает правила из конфигурации
+func DefaultRules() model.FilterRules {
+	conf := config.File.FilterConfig
+
+	return model.FilterRules{
+		MaxLinks:           conf.MaxLinks,
+		MaxLinkDensity:     conf.MaxLinkDensity,
+		BannedWords:        conf.BannedWords,
+		BannedPatterns:     conf.BannedPatterns,
+		DuplicateWindow:    conf.DuplicateWindow,
+		DuplicateMinLength: conf.DuplicateMinLength,
+		PostsPerHour:       conf.PostsPerHour,
+		CommentsPerHour:    conf.CommentsPerHour,
+		ProbationDays:      conf.ProbationDays,
+		ProbationMaxLinks:  conf.ProbationMaxLinks,
+	}
+}
@@ -0,0 +1,3 @@
+// Package integration содержит тесты, которым нужна настоящая база данных.
+// Это отдельный модуль, чтобы тестовый драйвер SQLite не попадал в зависимости приложения.
+package integration
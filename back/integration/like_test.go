@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"app/auth"
+	"app/config"
+	"app/db"
+	"app/log"
+	"app/model"
+	"app/web"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupLikeTest поднимает файловую SQLite через драйвер modernc.org/sqlite, которому не нужен cgo.
+// В отличие от базы в памяти, несколько соединений здесь действительно работают параллельно.
+func setupLikeTest(t *testing.T) (*web.WebApp, model.Post, []string) {
+	t.Helper()
+
+	log.App = log.NewConsoleLogger()
+	config.File.JWTTokenPassword = "test"
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "likes.db") + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+	gormDB, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.App = &db.DataBase{DB: gormDB}
+	if err := db.App.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	author := model.User{Name: "author", Email: "author@test", Password: "x", Role: model.UserRole}
+	if err := db.App.Create(&author).Error; err != nil {
+		t.Fatal(err)
+	}
+	post := model.Post{Title: "Пост", Content: "Текст", AuthorID: author.ID}
+	if err := db.App.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := make([]string, 0, 20)
+	for i := 0; i < cap(tokens); i++ {
+		user := model.User{Name: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@test", i), Password: "x", Role: model.UserRole}
+		if err := db.App.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		token, err := auth.CreateJWTToken(&user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return web.NewWebSocketServer(), post, tokens
+}
+
+// hammer отправляет каждым токеном times одновременных запросов на path
+func hammer(t *testing.T, app *web.WebApp, path string, postID uint, tokens []string, times int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for _, token := range tokens {
+		for i := 0; i < times; i++ {
+			wg.Add(1)
+			go func(token string) {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(fmt.Sprintf(`{"postId":"%d"}`, postID)))
+				req.AddCookie(&http.Cookie{Name: "authToken", Value: token})
+				rec := httptest.NewRecorder()
+				app.Router.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Errorf("%s: статус %d: %s", path, rec.Code, rec.Body.String())
+				}
+			}(token)
+		}
+	}
+	wg.Wait()
+}
+
+// checkLikes проверяет количество лайков в таблице и счетчик поста
+func checkLikes(t *testing.T, postID uint, want int) {
+	t.Helper()
+
+	var rows int64
+	if err := db.App.Model(&model.Like{}).Where("post_id = ?", postID).Count(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	var post model.Post
+	if err := db.App.First(&post, postID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rows != int64(want) || post.LikesCount != want {
+		t.Fatalf("лайков в таблице %d, счетчик %d, ожидалось %d", rows, post.LikesCount, want)
+	}
+}
+
+func TestConcurrentLikes(t *testing.T) {
+	app, post, tokens := setupLikeTest(t)
+
+	// Каждый пользователь много раз подряд нажимает на лайк
+	hammer(t, app, "/api/put-like", post.ID, tokens, 5)
+	checkLikes(t, post.ID, len(tokens))
+
+	// Половина пользователей так же снимает лайк
+	hammer(t, app, "/api/down-like", post.ID, tokens[:len(tokens)/2], 5)
+	checkLikes(t, post.ID, len(tokens)-len(tokens)/2)
+
+	// Снятые и поставленные одновременно лайки не сбивают счетчик
+	var wg sync.WaitGroup
+	for _, path := range []string{"/api/put-like", "/api/down-like"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			hammer(t, app, path, post.ID, tokens, 3)
+		}(path)
+	}
+	wg.Wait()
+
+	var rows int64
+	if err := db.App.Model(&model.Like{}).Where("post_id = ?", post.ID).Count(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	checkLikes(t, post.ID, int(rows))
+
+	fixed, err := auth.ReconcileLikesCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fixed != 0 {
+		t.Fatalf("пересчет исправил %d постов, счетчик должен был сходиться", fixed)
+	}
+}
+
+func TestReconcileLikesCount(t *testing.T) {
+	_, post, _ := setupLikeTest(t)
+
+	if err := db.App.Model(&post).UpdateColumn("likes_count", 42).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	fixed, err := auth.ReconcileLikesCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fixed != 1 {
+		t.Fatalf("исправлено постов %d, ожидался 1", fixed)
+	}
+	checkLikes(t, post.ID, 0)
+}
@@ -0,0 +1,214 @@
+// В данном пакете реализуется рассылка живых обновлений подключенным клиентам.
+package live
+
+import (
+	"app/model"
+	"sync"
+)
+
+// Hub рассылает события подключенным клиентам и хранит последние события для переподключения.
+// Пустой *Hub ничего не рассылает, поэтому публиковать события можно и там, где живые обновления не запущены.
+type Hub struct {
+	sendBuffer int
+
+	mu       sync.Mutex
+	lastID   uint64
+	log      []entry // Кольцевой буфер последних событий
+	logStart int     // Индекс самого старого события в log
+	logSize  int
+	clients  map[*Client]struct{}
+}
+
+// entry событие вместе с тем, кому оно адресовано
+type entry struct {
+	event  model.LiveEvent
+	postID uint          // Пост, реакции которого изменились
+	userID uint          // Получатель уведомления
+	hidden map[uint]bool // Пользователи, которым не показывается новый пост
+}
+
+// Client подключение одного клиента. События, которые клиент не успевает забирать, копятся в буфере;
+// когда буфер заполнен, клиент отключается, чтобы медленное соединение не задерживало рассылку.
+type Client struct {
+	userID uint
+	send   chan model.LiveEvent
+	done   chan struct{}
+
+	mu    sync.Mutex
+	posts map[uint]bool // Посты, за реакциями которых следит клиент
+}
+
+// NewHub создает рассылку с буфером sendBuffer событий на клиента, хранящую logSize последних событий
+func NewHub(sendBuffer, logSize int) *Hub {
+	if sendBuffer < 1 {
+		sendBuffer = 1
+	}
+	return &Hub{
+		sendBuffer: sendBuffer,
+		logSize:    logSize,
+		clients:    make(map[*Client]struct{}),
+	}
+}
+
+// Register подключает клиента пользователя userID, следящего за реакциями постов postIDs.
+// Если lastEventID не 0, клиент сначала получает сохраненные события после lastEventID,
+// а если часть из них уже не хранится - событие LiveReset.
+func (h *Hub) Register(userID uint, lastEventID uint64, postIDs []uint) *Client {
+	client := &Client{
+		userID: userID,
+		done:   make(chan struct{}),
+		posts:  make(map[uint]bool),
+	}
+	client.Subscribe(postIDs)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Пропущенные события добавляются под той же блокировкой, что и рассылка,
+	// поэтому клиент не теряет и не получает дважды событие, опубликованное во время подключения
+	var missed []model.LiveEvent
+	if lastEventID != 0 {
+		oldestID := h.lastID + 1
+		if len(h.log) > 0 {
+			oldestID = h.log[h.logStart].event.ID
+		}
+
+		// Номер из будущего означает, что сервер перезапускался и нумерация началась заново
+		if lastEventID > h.lastID || lastEventID+1 < oldestID {
+			missed = append(missed, model.LiveEvent{ID: h.lastID, Type: model.LiveReset})
+		} else {
+			for i := range h.log {
+				logged := h.log[(h.logStart+i)%len(h.log)]
+				if logged.event.ID > lastEventID && client.wants(logged) {
+					missed = append(missed, logged.event)
+				}
+			}
+		}
+	}
+
+	client.send = make(chan model.LiveEvent, h.sendBuffer+len(missed))
+	for _, event := range missed {
+		client.send <- event
+	}
+	h.clients[client] = struct{}{}
+	return client
+}
+
+// Unregister отключает клиента. Повторное отключение ничего не делает.
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unregister(client)
+}
+
+func (h *Hub) unregister(client *Client) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.done)
+	}
+}
+
+// Clients возвращает количество подключенных клиентов
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// publish присваивает событию номер, сохраняет его и отправляет клиентам, которым оно адресовано
+func (h *Hub) publish(logged entry) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	logged.event.ID = h.lastID
+	if len(h.log) < h.logSize {
+		h.log = append(h.log, logged)
+	} else if h.logSize > 0 {
+		h.log[h.logStart] = logged
+		h.logStart = (h.logStart + 1) % h.logSize
+	}
+
+	for client := range h.clients {
+		if !client.wants(logged) {
+			continue
+		}
+		select {
+		case client.send <- logged.event:
+		default:
+			h.unregister(client)
+		}
+	}
+}
+
+// PublishNewPost сообщает о новом посте всем клиентам, кроме клиентов пользователей hiddenFrom
+func (h *Hub) PublishNewPost(post model.PostForFeed, hiddenFrom []uint) {
+	hidden := make(map[uint]bool, len(hiddenFrom))
+	for _, userID := range hiddenFrom {
+		hidden[userID] = true
+	}
+	h.publish(entry{event: model.LiveEvent{Type: model.LiveNewPost, Data: post}, hidden: hidden})
+}
+
+// PublishLikes сообщает об изменении реакций поста клиентам, которые следят за ним
+func (h *Hub) PublishLikes(likes model.LiveLikesJson) {
+	h.publish(entry{event: model.LiveEvent{Type: model.LiveLikes, Data: likes}, postID: likes.PostID})
+}
+
+// PublishNotification сообщает пользователю userID о новом уведомлении на всех его подключениях
+func (h *Hub) PublishNotification(userID uint, notification model.LiveNotificationJson) {
+	h.publish(entry{event: model.LiveEvent{Type: model.LiveNotification, Data: notification}, userID: userID})
+}
+
+// wants проверяет, адресовано ли событие клиенту
+func (c *Client) wants(logged entry) bool {
+	switch logged.event.Type {
+	case model.LiveLikes:
+		return c.Watching(logged.postID)
+	case model.LiveNotification:
+		return c.userID == logged.userID
+	case model.LiveNewPost:
+		return !logged.hidden[c.userID]
+	default:
+		return true
+	}
+}
+
+// Events возвращает канал событий клиента
+func (c *Client) Events() <-chan model.LiveEvent {
+	return c.send
+}
+
+// Done закрывается, когда клиент отключен, в том числе из-за переполнения буфера
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Subscribe добавляет посты, за реакциями которых следит клиент
+func (c *Client) Subscribe(postIDs []uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range postIDs {
+		c.posts[id] = true
+	}
+}
+
+// Unsubscribe убирает посты, за реакциями которых следит клиент
+func (c *Client) Unsubscribe(postIDs []uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range postIDs {
+		delete(c.posts, id)
+	}
+}
+
+// Watching проверяет, следит ли клиент за реакциями поста
+func (c *Client) Watching(postID uint) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.posts[postID]
+}
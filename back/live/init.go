@@ -0,0 +1,12 @@
+package live
+
+import "app/config"
+
+var App *Hub
+
+func Init() error {
+	conf := config.File.LiveConfig
+
+	App = NewHub(conf.SendBuffer, conf.EventLog)
+	return nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"app/auth"
 	"app/cache"
 	"app/config"
 	"app/db"
 	_ "app/docs" // Не удалять. Для SWAGGER!
+	"app/filter"
+	"app/live"
 	"app/log"
+	"app/markdown"
+	"app/media"
+	"app/pow"
+	"app/ratelimit"
 	"app/smtp"
+	"app/trash"
 	u "app/utils"
 	"app/web"
+	"flag"
 )
 
 // @title SCRIBBLE
@@ -18,6 +27,8 @@ import (
 
 // @BasePath /api/v1
 func main() {
+	reconcileLikes := flag.Bool("reconcile-likes", false, "пересчитать количество лайков постов по таблице лайков и завершить работу")
+	flag.Parse()
 
 	u.HandleFatalError(log.Init())
 
@@ -29,8 +40,34 @@ func main() {
 
 	u.HandleFatalError(smtp.Init())
 
+	u.HandleFatalError(pow.Init())
+
+	u.HandleFatalError(ratelimit.Init())
+
+	u.HandleFatalError(markdown.Init())
+
+	u.HandleFatalError(media.Init())
+
 	u.HandleFatalError(db.Init())
 
+	if *reconcileLikes {
+		_, err := auth.ReconcileLikesCount()
+		u.HandleFatalError(err)
+		return
+	}
+
+	u.HandleFatalError(auth.BackfillPostSlugs())
+
+	u.HandleFatalError(auth.BackfillUsernames())
+
+	u.HandleFatalError(media.InitWorkers(auth.ProcessMedia, auth.PendingMedia))
+
+	u.HandleFatalError(filter.Init())
+
+	u.HandleFatalError(trash.Init())
+
+	u.HandleFatalError(live.Init())
+
 	u.HandleFatalError(web.Init())
 
 	u.HandleFatalError(web.App.StartServer())
@@ -0,0 +1,111 @@
+// В данном пакете реализуется преобразование Markdown в безопасный HTML.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Renderer преобразует Markdown в HTML и очищает результат по списку разрешенных элементов
+type Renderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+	strict   *bluemonday.Policy
+}
+
+// NewRenderer создает Markdown-рендерер: CommonMark, таблицы, сноски, подсветка кода, якоря заголовков, упоминания и ссылки на загруженные файлы
+func NewRenderer() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle("monokai"),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithInlineParsers(util.Prioritized(&mentionParser{}, 500)),
+		),
+		goldmark.WithRendererOptions(
+			// Сырой HTML из источника пропускается, но затем очищается политикой ниже
+			goldmarkhtml.WithUnsafe(),
+		),
+	)
+
+	return &Renderer{
+		markdown: md,
+		policy:   newPolicy(),
+		strict:   bluemonday.StrictPolicy(),
+	}
+}
+
+// newPolicy создает список разрешенных элементов и атрибутов для отрендеренного HTML
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+
+	// Классы подсветки синтаксиса
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span", "div", "a", "sup", "ol", "li", "hr")
+
+	// Якоря заголовков и сносок
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[a-zA-Z0-9:_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "sup", "li")
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div", "sup")
+
+	// Выравнивание столбцов таблиц
+	policy.AllowAttrs("style").Matching(regexp.MustCompile(`^text-align:(left|right|center)$`)).OnElements("th", "td")
+
+	// Списки задач
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return policy
+}
+
+// Render преобразует Markdown в очищенный HTML. Упоминания и ссылки на загруженные файлы остаются текстом.
+func (r *Renderer) Render(source string) (string, error) {
+	return r.RenderLinks(source, Links{})
+}
+
+// Links адреса, которые подставляются в текст при рендеринге
+type Links struct {
+	Profiles map[string]string // Ссылки на профили упомянутых пользователей по имени
+	Media    map[uint]string   // Адреса загруженных файлов по ID
+}
+
+// RenderLinks преобразует Markdown в очищенный HTML, заменяя упоминания ссылками на профили,
+// а ссылки media:ID - адресами загруженных файлов. Неизвестные упоминания и файлы остаются текстом.
+func (r *Renderer) RenderLinks(source string, links Links) (string, error) {
+	src := []byte(source)
+	doc := r.markdown.Parser().Parse(text.NewReader(src))
+
+	replaceMentions(doc, links.Profiles)
+	replaceMedia(doc, links.Media)
+
+	var buf bytes.Buffer
+	if err := r.markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", err
+	}
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+// angleBrackets удаляет угловые скобки, оставшиеся после раскодирования сущностей
+var angleBrackets = strings.NewReplacer("<", "", ">", "")
+
+// PlainText удаляет из строки любую HTML-разметку и возвращает текст. Сущности раскодируются, но угловые скобки
+// из них удаляются, иначе &lt;img&gt; превратилось бы в настоящий тег.
+func (r *Renderer) PlainText(text string) string {
+	return angleBrackets.Replace(html.UnescapeString(r.strict.Sanitize(text)))
+}
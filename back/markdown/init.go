@@ -0,0 +1,8 @@
+package markdown
+
+var App *Renderer
+
+func Init() error {
+	App = NewRenderer()
+	return nil
+}
@@ -0,0 +1,101 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MediaScheme схема ссылок на загруженные файлы: ![описание](media:42), где 42 - ID файла
+const MediaScheme = "media:"
+
+// MediaLink возвращает Markdown для вставки загруженного файла id в текст
+func MediaLink(id uint, alt string) string {
+	alt = strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(alt)
+	return "![" + alt + "](" + MediaScheme + strconv.FormatUint(uint64(id), 10) + ")"
+}
+
+// mediaRef ссылка или изображение со ссылкой на загруженный файл
+type mediaRef struct {
+	node ast.Node
+	id   uint
+}
+
+// parseMediaID возвращает ID файла из адреса media:ID
+func parseMediaID(destination []byte) (uint, bool) {
+	dest := string(destination)
+	if !strings.HasPrefix(dest, MediaScheme) {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(dest, MediaScheme), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// collectMediaRefs возвращает изображения и ссылки документа, указывающие на загруженные файлы
+func collectMediaRefs(doc ast.Node) []mediaRef {
+	var refs []mediaRef
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var destination []byte
+		switch node := n.(type) {
+		case *ast.Image:
+			destination = node.Destination
+		case *ast.Link:
+			destination = node.Destination
+		default:
+			return ast.WalkContinue, nil
+		}
+		if id, ok := parseMediaID(destination); ok {
+			refs = append(refs, mediaRef{node: n, id: id})
+		}
+		return ast.WalkContinue, nil
+	})
+	return refs
+}
+
+// MediaRefs возвращает ID загруженных файлов, на которые ссылается текст, без повторов в порядке первой ссылки
+func (r *Renderer) MediaRefs(source string) []uint {
+	doc := r.markdown.Parser().Parse(text.NewReader([]byte(source)))
+
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, ref := range collectMediaRefs(doc) {
+		if seen[ref.id] {
+			continue
+		}
+		seen[ref.id] = true
+		ids = append(ids, ref.id)
+	}
+	return ids
+}
+
+// replaceMedia подставляет адреса загруженных файлов. Ссылка на неизвестный файл заменяется своим текстом.
+func replaceMedia(doc ast.Node, media map[uint]string) {
+	for _, ref := range collectMediaRefs(doc) {
+		url, ok := media[ref.id]
+		if ok {
+			switch node := ref.node.(type) {
+			case *ast.Image:
+				node.Destination = []byte(url)
+			case *ast.Link:
+				node.Destination = []byte(url)
+			}
+			continue
+		}
+
+		parent := ref.node.Parent()
+		for child := ref.node.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			parent.InsertBefore(parent, ref.node, child)
+			child = next
+		}
+		parent.RemoveChild(parent, ref.node)
+	}
+}
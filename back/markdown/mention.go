@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// kindMention вид узла упоминания
+var kindMention = ast.NewNodeKind("Mention")
+
+// mention упоминание пользователя @username. Перед рендерингом узел заменяется ссылкой на профиль
+// или, если пользователь не найден, обычным текстом.
+type mention struct {
+	ast.BaseInline
+	Username string // Имя пользователя в нижнем регистре
+	Raw      string // Упоминание так, как оно написано в тексте
+}
+
+func (n *mention) Kind() ast.NodeKind {
+	return kindMention
+}
+
+func (n *mention) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Username": n.Username}, nil)
+}
+
+// isUsernameByte проверяет, может ли символ входить в имя пользователя
+func isUsernameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// mentionParser находит упоминания @username в тексте. Код разбирается раньше, поэтому упоминания в нем не находятся.
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// Упоминание начинается с начала слова, поэтому адреса почты не считаются упоминаниями
+	if prev := block.PrecendingCharacter(); unicode.IsLetter(prev) || unicode.IsDigit(prev) || strings.ContainsRune("_-@./", prev) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	end := 1
+	for end < len(line) && isUsernameByte(line[end]) {
+		end++
+	}
+	// Дефис или подчеркивание в конце скорее знак препинания, чем часть имени
+	username := strings.TrimRight(string(line[1:end]), "_-")
+	if username == "" {
+		return nil
+	}
+
+	block.Advance(1 + len(username))
+	return &mention{
+		Username: strings.ToLower(username),
+		Raw:      "@" + username,
+	}
+}
+
+// collectMentions возвращает узлы упоминаний документа в порядке следования
+func collectMentions(doc ast.Node) []*mention {
+	var mentions []*mention
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if m, ok := n.(*mention); ok && entering {
+			mentions = append(mentions, m)
+		}
+		return ast.WalkContinue, nil
+	})
+	return mentions
+}
+
+// insideLink проверяет, находится ли узел внутри ссылки
+func insideLink(n ast.Node) bool {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		switch p.Kind() {
+		case ast.KindLink, ast.KindAutoLink:
+			return true
+		}
+	}
+	return false
+}
+
+// Mentions возвращает имена упомянутых в тексте пользователей без повторов в порядке первого упоминания.
+// Упоминания внутри ссылок не учитываются: ссылкой на профиль они не станут.
+func (r *Renderer) Mentions(source string) []string {
+	doc := r.markdown.Parser().Parse(text.NewReader([]byte(source)))
+
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, m := range collectMentions(doc) {
+		if insideLink(m) || seen[m.Username] {
+			continue
+		}
+		seen[m.Username] = true
+		usernames = append(usernames, m.Username)
+	}
+	return usernames
+}
+
+// RenderMentions преобразует Markdown в очищенный HTML, заменяя упоминания ссылками на профили.
+// profiles сопоставляет имени пользователя ссылку на его профиль; остальные упоминания остаются текстом.
+func (r *Renderer) RenderMentions(source string, profiles map[string]string) (string, error) {
+	return r.RenderLinks(source, Links{Profiles: profiles})
+}
+
+// replaceMentions заменяет упоминания ссылками на профили из profiles, остальные упоминания - текстом
+func replaceMentions(doc ast.Node, profiles map[string]string) {
+	for _, m := range collectMentions(doc) {
+		parent := m.Parent()
+		url, ok := profiles[m.Username]
+		if !ok || insideLink(m) {
+			parent.ReplaceChild(parent, m, ast.NewString([]byte(m.Raw)))
+			continue
+		}
+
+		link := ast.NewLink()
+		link.Destination = []byte(url)
+		link.SetAttributeString("class", []byte("mention"))
+		link.AppendChild(link, ast.NewString([]byte(m.Raw)))
+		parent.ReplaceChild(parent, m, link)
+	}
+}
@@ -0,0 +1,140 @@
+package markdown
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Heading заголовок для оглавления
+type Heading struct {
+	Level  int    // Уровень заголовка от 1 до 6
+	Text   string // Текст заголовка
+	Anchor string // Якорь заголовка, пустой, если у заголовка нет id
+}
+
+// Summary сведения о тексте поста для ленты и оглавления
+type Summary struct {
+	Excerpt   string    // Начало текста без разметки
+	WordCount int       // Количество слов
+	Headings  []Heading // Заголовки в порядке следования
+}
+
+// headingLevels уровни заголовков по тегам
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// skippedElements элементы, текст которых не попадает в отрывок
+var skippedElements = map[atom.Atom]bool{
+	atom.Pre: true, atom.Code: true, atom.Table: true, atom.Sup: true,
+}
+
+// Summarize собирает отрывок, количество слов и заголовки из отрендеренного HTML.
+// Отрывок составляется из текста абзацев, обрезается по границе слова до excerptLength символов.
+func Summarize(renderedHTML string, excerptLength int) Summary {
+	var (
+		summary   Summary
+		paragraph strings.Builder
+		heading   *Heading
+		headText  strings.Builder
+		skipDepth int
+		excerpt   []string
+		allText   strings.Builder
+	)
+
+	tokenizer := html.NewTokenizer(strings.NewReader(renderedHTML))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tt {
+		case html.StartTagToken:
+			if skippedElements[token.DataAtom] {
+				skipDepth++
+			}
+			if level, ok := headingLevels[token.DataAtom]; ok {
+				heading = &Heading{Level: level}
+				headText.Reset()
+				for _, attr := range token.Attr {
+					if attr.Key == "id" {
+						heading.Anchor = attr.Val
+					}
+				}
+			}
+			if token.DataAtom == atom.P {
+				paragraph.Reset()
+			}
+		case html.EndTagToken:
+			if skippedElements[token.DataAtom] && skipDepth > 0 {
+				skipDepth--
+			}
+			if _, ok := headingLevels[token.DataAtom]; ok && heading != nil {
+				heading.Text = strings.Join(strings.Fields(headText.String()), " ")
+				if heading.Text != "" {
+					summary.Headings = append(summary.Headings, *heading)
+				}
+				heading = nil
+			}
+			if token.DataAtom == atom.P {
+				if text := strings.Join(strings.Fields(paragraph.String()), " "); text != "" {
+					excerpt = append(excerpt, text)
+				}
+			}
+		case html.TextToken:
+			summary.WordCount += len(strings.Fields(token.Data))
+			if heading != nil {
+				headText.WriteString(token.Data)
+			}
+			if skipDepth == 0 {
+				paragraph.WriteString(token.Data)
+				allText.WriteString(token.Data)
+				allText.WriteString(" ")
+			}
+		}
+	}
+
+	// Текст без абзацев, например только списки, целиком идет в отрывок
+	text := strings.Join(excerpt, " ")
+	if text == "" {
+		text = strings.Join(strings.Fields(allText.String()), " ")
+	}
+
+	summary.Excerpt = truncateWords(text, excerptLength)
+	return summary
+}
+
+// ReadingTime возвращает время чтения в минутах при скорости wordsPerMinute, не меньше минуты
+func ReadingTime(wordCount, wordsPerMinute int) int {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = 200
+	}
+	minutes := int(math.Ceil(float64(wordCount) / float64(wordsPerMinute)))
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// truncateWords обрезает текст до limit символов по границе слова и добавляет многоточие
+func truncateWords(text string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := limit
+	for cut > 0 && runes[cut] != ' ' {
+		cut--
+	}
+	if cut == 0 {
+		cut = limit
+	}
+	return strings.TrimRight(string(runes[:cut]), " ,.;:!?-") + "…"
+}
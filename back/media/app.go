@@ -0,0 +1,53 @@
+// В данном пакете реализуется хранение загруженных файлов. Хранилище подключается через интерфейс Storage:
+// каталог на диске сервера или S3-совместимое хранилище.
+package media
+
+import (
+	"errors"
+	"io"
+)
+
+// URLPrefix путь, по которому приложение само отдает файлы из хранилища
+const URLPrefix = "/media/"
+
+// ErrNotFound файла нет в хранилище
+var ErrNotFound = errors.New("Файл не найден")
+
+// Storage хранилище файлов. Имя файла key состоит из латинских букв, цифр, точки, дефиса и подчеркивания.
+type Storage interface {
+	// Put сохраняет файл, заменяя существующий с тем же именем
+	Put(key, contentType string, data []byte) error
+	// Open открывает файл для чтения. Если файла нет, возвращает ErrNotFound.
+	Open(key string) (io.ReadCloser, error)
+	// Delete удаляет файл. Удаление отсутствующего файла не считается ошибкой.
+	Delete(key string) error
+	// URL возвращает адрес, по которому читатели получают файл
+	URL(key string) string
+}
+
+// extensions расширения имен файлов для разрешенных типов
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Extension возвращает расширение имени файла для типа contentType или пустую строку, если тип неизвестен
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
+
+// validKey проверяет, что имя файла не выходит за пределы хранилища
+func validKey(key string) bool {
+	if key == "" || key[0] == '.' {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Декодер GIF для image.Decode
+	"image/jpeg"
+	"image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Декодер WebP для image.Decode
+)
+
+// Size вариант изображения, который нужно создать
+type Size struct {
+	Name    string // thumbnail, medium или large
+	MaxSide int    // Длинная сторона в пикселях
+}
+
+// Variant созданный вариант изображения
+type Variant struct {
+	Name   string
+	Format string // jpeg, png или webp
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Options параметры обработки изображения
+type Options struct {
+	Sizes       []Size // Варианты от меньшего к большему
+	JPEGQuality int
+	MaxPixels   int // Максимальное количество пикселей, 0 - без ограничения
+}
+
+// Processed обработанное изображение
+type Processed struct {
+	Original []byte // Исходное изображение без метаданных
+	Width    int    // Ширина с учетом поворота из EXIF
+	Height   int    // Высота с учетом поворота из EXIF
+	Blurhash string
+	Variants []Variant
+}
+
+// ContentType возвращает тип файла для формата варианта
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+// DecodeConfig проверяет, что data - изображение не больше maxPixels пикселей, и возвращает его размеры.
+// Само изображение не декодируется, поэтому проверка дешевая и защищает от огромных изображений в маленьком файле.
+func DecodeConfig(data []byte, maxPixels int) (int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("Не удалось прочитать изображение: %v", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return 0, 0, fmt.Errorf("Изображение без размеров")
+	}
+	if maxPixels > 0 && config.Width*config.Height > maxPixels {
+		return 0, 0, fmt.Errorf("Изображение %dx%d слишком большое", config.Width, config.Height)
+	}
+	return config.Width, config.Height, nil
+}
+
+// Process удаляет метаданные изображения, поворачивает его по ориентации из EXIF, создает уменьшенные
+// варианты в исходном формате и WebP и считает BlurHash. Вариант, который не меньше предыдущего, не создается.
+func Process(contentType string, data []byte, opts Options) (*Processed, error) {
+	if _, _, err := DecodeConfig(data, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Не удалось прочитать изображение: %v", err)
+	}
+
+	result := &Processed{}
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	if orientation != 1 {
+		// Ориентация хранится в EXIF, поэтому без EXIF изображение нужно повернуть и сжать заново
+		img = orient(img, orientation)
+		if result.Original, err = encode(img, "jpeg", max(opts.JPEGQuality, 90)); err != nil {
+			return nil, err
+		}
+	} else if result.Original, err = StripMetadata(contentType, data); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	result.Width, result.Height = bounds.Dx(), bounds.Dy()
+	longSide := max(result.Width, result.Height)
+
+	// Прозрачные изображения сохраняются в PNG, остальные - в JPEG
+	format := "jpeg"
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		format = "png"
+	}
+
+	for i, size := range opts.Sizes {
+		if i > 0 && longSide <= opts.Sizes[i-1].MaxSide {
+			break
+		}
+		resized := resize(img, size.MaxSide)
+		for _, f := range []string{format, "webp"} {
+			encoded, err := encode(resized, f, opts.JPEGQuality)
+			if err != nil {
+				return nil, err
+			}
+			result.Variants = append(result.Variants, Variant{
+				Name:   size.Name,
+				Format: f,
+				Data:   encoded,
+				Width:  resized.Bounds().Dx(),
+				Height: resized.Bounds().Dy(),
+			})
+		}
+	}
+
+	// Для заглушки хватает крошечной копии, составляющих больше по длинной стороне
+	xComponents, yComponents := 4, 3
+	if result.Height > result.Width {
+		xComponents, yComponents = 3, 4
+	}
+	result.Blurhash = Blurhash(resize(img, 32), xComponents, yComponents)
+
+	return result, nil
+}
+
+// resize уменьшает изображение так, чтобы длинная сторона была не больше maxSide. Маленькие изображения не увеличиваются.
+func resize(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// orient поворачивает и отражает изображение по ориентации из EXIF
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	// source возвращает точку исходного изображения для точки (x, y) результата
+	source := func(x, y int) (int, int) {
+		switch orientation {
+		case 2:
+			return width - 1 - x, y
+		case 3:
+			return width - 1 - x, height - 1 - y
+		case 4:
+			return x, height - 1 - y
+		case 5:
+			return y, x
+		case 6:
+			return y, height - 1 - x
+		case 7:
+			return width - 1 - y, height - 1 - x
+		case 8:
+			return width - 1 - y, x
+		}
+		return x, y
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			sx, sy := source(x, y)
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// encode сжимает изображение в формат format. WebP сохраняется без потерь: кодировщика со сжатием
+// с потерями без cgo нет.
+func encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		err = fmt.Errorf("Неизвестный формат изображения: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
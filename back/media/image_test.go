@@ -0,0 +1,157 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// gpsMarker строка в метаданных, по которой видно, что они остались в файле
+const gpsMarker = "GPS-55.7558N-37.6173E"
+
+// exifSegment возвращает сегмент APP1 с EXIF: ориентация orientation и ссылка на GPS IFD с широтой 55°45'21"
+func exifSegment(orientation uint16) []byte {
+	order := binary.BigEndian
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+
+	// IFD0: ориентация, ссылка на GPS IFD и описание изображения с маркером
+	const ifd0 = 8
+	const gpsIFD = ifd0 + 2 + 3*12 + 4
+	const gpsData = gpsIFD + 2 + 2*12 + 4
+	const description = gpsData + 3*8
+	entry := func(tag, kind uint16, count, value uint32) []byte {
+		e := make([]byte, 12)
+		order.PutUint16(e, tag)
+		order.PutUint16(e[2:], kind)
+		order.PutUint32(e[4:], count)
+		order.PutUint32(e[8:], value)
+		return e
+	}
+
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = append(tiff, entry(0x0112, 3, 1, uint32(orientation)<<16)...)
+	tiff = append(tiff, entry(0x010E, 2, uint32(len(gpsMarker)+1), description)...)
+	tiff = append(tiff, entry(0x8825, 4, 1, gpsIFD)...)
+	tiff = order.AppendUint32(tiff, 0)
+
+	// GPS IFD: северная широта в градусах, минутах и секундах
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = append(tiff, entry(0x0001, 2, 2, uint32('N')<<24)...)
+	tiff = append(tiff, entry(0x0002, 5, 3, gpsData)...)
+	tiff = order.AppendUint32(tiff, 0)
+	for _, value := range []uint32{55, 45, 21} {
+		tiff = order.AppendUint32(tiff, value)
+		tiff = order.AppendUint32(tiff, 1)
+	}
+	tiff = append(tiff, gpsMarker+"\x00"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = order.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// xmpSegment возвращает сегмент APP1 с XMP, в котором тоже записаны координаты
+func xmpSegment() []byte {
+	payload := []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><exif:GPSLatitude>" + gpsMarker + "</exif:GPSLatitude></x:xmpmeta>")
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// photoJPEG возвращает снимок width x height с EXIF, GPS и XMP, как его сохраняет камера телефона
+func photoJPEG(t *testing.T, width, height int, orientation uint16) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / width), uint8(y * 255 / height), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buf.Bytes()
+	photo := append([]byte{}, encoded[:2]...)
+	photo = append(photo, exifSegment(orientation)...)
+	photo = append(photo, xmpSegment()...)
+	return append(photo, encoded[2:]...)
+}
+
+// checkNoGPS проверяет, что в файле не осталось ни EXIF, ни координат
+func checkNoGPS(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	for _, leak := range []string{gpsMarker, "Exif\x00\x00", "ns.adobe.com/xap"} {
+		if bytes.Contains(data, []byte(leak)) {
+			t.Errorf("%s: в файле осталось %q", name, leak)
+		}
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("%s: файл не читается: %v", name, err)
+	}
+}
+
+func TestStripMetadataRemovesGPS(t *testing.T) {
+	photo := photoJPEG(t, 16, 8, 1)
+	if !bytes.Contains(photo, []byte(gpsMarker)) || jpegOrientation(photo) != 1 {
+		t.Fatal("в тестовом снимке нет метаданных")
+	}
+
+	stripped, err := StripMetadata("image/jpeg", photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNoGPS(t, "jpeg", stripped)
+
+	// Сжатые данные не перекодируются: файл меньше ровно на размер удаленных сегментов
+	if removed := len(photo) - len(stripped); removed != len(exifSegment(1))+len(xmpSegment()) {
+		t.Errorf("удалено %d байт, ожидалось %d", removed, len(exifSegment(1))+len(xmpSegment()))
+	}
+}
+
+func TestProcessRemovesGPS(t *testing.T) {
+	opts := Options{
+		Sizes:       []Size{{Name: "thumbnail", MaxSide: 8}, {Name: "medium", MaxSide: 24}},
+		JPEGQuality: 80,
+	}
+
+	for _, orientation := range []uint16{1, 6} {
+		photo := photoJPEG(t, 40, 20, orientation)
+		if got := jpegOrientation(photo); got != int(orientation) {
+			t.Fatalf("ориентация тестового снимка %d, ожидалась %d", got, orientation)
+		}
+
+		processed, err := Process("image/jpeg", photo, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkNoGPS(t, "исходный файл", processed.Original)
+		for _, variant := range processed.Variants {
+			checkNoGPS(t, variant.Name+"."+variant.Format, variant.Data)
+		}
+
+		// Ориентация 6 - поворот на 90°: после удаления EXIF изображение должно быть повернуто в пикселях
+		width, height := 40, 20
+		if orientation == 6 {
+			width, height = height, width
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(processed.Original))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if processed.Width != width || processed.Height != height || config.Width != width || config.Height != height {
+			t.Errorf("ориентация %d: размер %dx%d, в файле %dx%d, ожидался %dx%d",
+				orientation, processed.Width, processed.Height, config.Width, config.Height, width, height)
+		}
+		if len(processed.Variants) != 4 || processed.Blurhash == "" {
+			t.Errorf("ориентация %d: вариантов %d, blurhash %q", orientation, len(processed.Variants), processed.Blurhash)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package media
+
+import (
+	"app/config"
+	"app/model"
+	"fmt"
+	"time"
+)
+
+// App хранилище загруженных файлов
+var App Storage
+
+// Workers фоновые обработчики изображений. nil, пока обработчики не запущены.
+var Workers *Pool
+
+func Init() error {
+	conf := config.File.MediaConfig
+
+	switch conf.Storage {
+	case model.MediaStorageLocal:
+		storage, err := NewLocalStorage(conf.LocalDir)
+		if err != nil {
+			return err
+		}
+		App = storage
+	case model.MediaStorageS3:
+		storage, err := NewS3Storage(conf.S3Endpoint, conf.S3Region, conf.S3Bucket, conf.S3AccessKey, conf.S3SecretKey, conf.S3PublicURL, conf.S3PathStyle)
+		if err != nil {
+			return err
+		}
+		App = storage
+	default:
+		return fmt.Errorf("Неизвестное хранилище файлов: %s", conf.Storage)
+	}
+	return nil
+}
+
+// InitWorkers запускает фоновую обработку изображений функцией process. pending возвращает файлы,
+// ожидающие обработки: они ставятся в очередь при запуске и затем каждые MEDIA_RESCAN_INTERVAL минут.
+func InitWorkers(process func(id uint) error, pending func() ([]uint, error)) error {
+	conf := config.File.MediaConfig
+	if conf.RescanInterval <= 0 {
+		return fmt.Errorf("Недопустимый интервал поиска необработанных изображений: %d. Укажите MEDIA_RESCAN_INTERVAL больше 0", conf.RescanInterval)
+	}
+
+	Workers = NewPool(conf.Workers, conf.QueueSize, process)
+	Workers.Watch(time.Duration(conf.RescanInterval)*time.Minute, pending)
+	return nil
+}
+
+// ProcessOptions возвращает параметры обработки изображений из конфигурации
+func ProcessOptions() Options {
+	conf := config.File.MediaConfig
+
+	return Options{
+		Sizes: []Size{
+			{Name: model.MediaThumbnail, MaxSide: conf.ThumbnailSize},
+			{Name: model.MediaMedium, MaxSide: conf.MediumSize},
+			{Name: model.MediaLarge, MaxSide: conf.LargeSize},
+		},
+		JPEGQuality: conf.JPEGQuality,
+		MaxPixels:   conf.MaxPixels * 1000000,
+	}
+}
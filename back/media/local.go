@@ -0,0 +1,91 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage хранилище в каталоге на диске сервера. Файлы отдает само приложение по адресу URLPrefix.
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage создает хранилище в каталоге dir, создавая каталог при необходимости
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Не удалось создать каталог файлов %s: %v", dir, err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// path возвращает путь к файлу key
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("Недопустимое имя файла: %s", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// Put сохраняет файл. Файл сначала пишется во временный, чтобы читатели не увидели его недописанным.
+func (s *LocalStorage) Put(key, contentType string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Open открывает файл для чтения
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete удаляет файл
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL возвращает адрес файла в приложении
+func (s *LocalStorage) URL(key string) string {
+	return URLPrefix + key
+}
@@ -0,0 +1,125 @@
+package media
+
+import (
+	"app/log"
+	"sync"
+	"time"
+)
+
+// Pool фоновые обработчики загруженных изображений. Обработка долгая, поэтому не выполняется в HTTP-запросе.
+type Pool struct {
+	jobs    chan uint
+	process func(id uint) error
+
+	mu      sync.Mutex
+	queued  map[uint]bool // Файлы в очереди или в обработке
+	stopped bool
+
+	stop    chan struct{}
+	workers sync.WaitGroup
+	watcher sync.WaitGroup
+}
+
+// NewPool запускает workers обработчиков. process обрабатывает файл с указанным ID,
+// очередь вмещает queueSize файлов.
+func NewPool(workers, queueSize int, process func(id uint) error) *Pool {
+	p := &Pool{
+		jobs:    make(chan uint, queueSize),
+		process: process,
+		queued:  make(map[uint]bool),
+		stop:    make(chan struct{}),
+	}
+	for i := 0; i < max(workers, 1); i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for id := range p.jobs {
+		if err := p.process(id); err != nil {
+			log.App.Error("Ошибка при обработке файла ", id, ": ", err)
+		}
+
+		p.mu.Lock()
+		delete(p.queued, id)
+		p.mu.Unlock()
+	}
+}
+
+// Enqueue ставит файл в очередь обработки. Файл, который уже в очереди или обрабатывается, второй раз не ставится.
+// Возвращает false, если обработчики не запущены или очередь заполнена: такой файл подберет следующая проверка Watch.
+func (p *Pool) Enqueue(id uint) bool {
+	if p == nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return false
+	}
+	if p.queued[id] {
+		return true
+	}
+	select {
+	case p.jobs <- id:
+		p.queued[id] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// Watch сразу и затем каждые interval ставит в очередь файлы, которые вернула pending. Так обрабатываются файлы,
+// не поместившиеся в очередь, и файлы, обработка которых не завершилась до остановки приложения.
+func (p *Pool) Watch(interval time.Duration, pending func() ([]uint, error)) {
+	p.watcher.Add(1)
+	go func() {
+		defer p.watcher.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.enqueuePending(pending)
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// enqueuePending ставит в очередь файлы, ожидающие обработки, пока в очереди есть место
+func (p *Pool) enqueuePending(pending func() ([]uint, error)) {
+	ids, err := pending()
+	if err != nil {
+		log.App.Error("Ошибка при получении необработанных файлов: ", err)
+		return
+	}
+	for i, id := range ids {
+		if !p.Enqueue(id) {
+			log.App.Info("Очередь обработки файлов заполнена, еще ", len(ids)-i, " файлов будут поставлены в очередь позже")
+			return
+		}
+	}
+}
+
+// Stop дожидается обработки файлов из очереди и останавливает обработчики
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.stop)
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.watcher.Wait()
+	p.workers.Wait()
+}
@@ -0,0 +1,68 @@
+package media
+
+import (
+	"app/log"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Файлы, не поместившиеся в очередь, обрабатываются после следующей проверки Watch, и каждый - один раз
+func TestPoolWatchPicksUpDroppedJobs(t *testing.T) {
+	log.App = log.NewConsoleLogger()
+
+	const files = 20
+	var mu sync.Mutex
+	processed := make(map[uint]int)
+	process := func(id uint) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		processed[id]++
+		mu.Unlock()
+		return nil
+	}
+	pending := func() ([]uint, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var ids []uint
+		for id := uint(1); id <= files; id++ {
+			if processed[id] == 0 {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	pool := NewPool(1, 2, process)
+	dropped := 0
+	for id := uint(1); id <= files; id++ {
+		if !pool.Enqueue(id) {
+			dropped++
+		}
+	}
+	if dropped == 0 {
+		t.Fatal("очередь из 2 файлов вместила все 20")
+	}
+
+	pool.Watch(5*time.Millisecond, pending)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if ids, _ := pending(); len(ids) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("не все файлы обработаны")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	pool.Stop()
+
+	for id, count := range processed {
+		if count != 1 {
+			t.Errorf("файл %d обработан %d раз", id, count)
+		}
+	}
+	if pool.Enqueue(1) {
+		t.Error("остановленные обработчики приняли файл")
+	}
+}
@@ -0,0 +1,6 @@
+package model
+
+type CommentConfig struct {
+	EditWindow int `envconfig:"COMMENT_EDIT_WINDOW" default:"15"`  // Время, в течение которого автор может изменить комментарий, в минутах
+	MaxLength  int `envconfig:"COMMENT_MAX_LENGTH" default:"5000"` // Максимальная длина комментария в символах
+}
@@ -0,0 +1,14 @@
+package model
+
+type FilterConfig struct {
+	MaxLinks           int      `envconfig:"FILTER_MAX_LINKS" default:"10"`             // Максимальное количество ссылок в тексте, 0 - без ограничения
+	MaxLinkDensity     int      `envconfig:"FILTER_MAX_LINK_DENSITY" default:"10"`      // Максимальное количество ссылок на 100 слов, 0 - без ограничения
+	BannedWords        []string `envconfig:"FILTER_BANNED_WORDS"`                       // Запрещенные слова и фразы через запятую
+	BannedPatterns     []string `envconfig:"FILTER_BANNED_PATTERNS"`                    // Запрещенные регулярные выражения через запятую
+	DuplicateWindow    int      `envconfig:"FILTER_DUPLICATE_WINDOW" default:"24"`      // Период поиска повторяющегося текста в часах, 0 - без проверки
+	DuplicateMinLength int      `envconfig:"FILTER_DUPLICATE_MIN_LENGTH" default:"100"` // Минимальная длина текста в символах для поиска повторов
+	PostsPerHour       int      `envconfig:"FILTER_POSTS_PER_HOUR" default:"5"`         // Максимальное количество постов пользователя в час, 0 - без ограничения
+	CommentsPerHour    int      `envconfig:"FILTER_COMMENTS_PER_HOUR" default:"30"`     // Максимальное количество комментариев пользователя в час, 0 - без ограничения
+	ProbationDays      int      `envconfig:"FILTER_PROBATION_DAYS" default:"3"`         // Испытательный срок нового аккаунта в днях
+	ProbationMaxLinks  int      `envconfig:"FILTER_PROBATION_MAX_LINKS" default:"0"`    // Максимальное количество ссылок в тексте аккаунта на испытательном сроке
+}
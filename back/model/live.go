@@ -0,0 +1,49 @@
+package model
+
+type LiveConfig struct {
+	AllowedOrigins []string `envconfig:"LIVE_ALLOWED_ORIGINS" default:""` // Сайты, с которых можно подключаться к живым обновлениям, кроме APP_URL. * - любые
+	PingInterval   int      `envconfig:"LIVE_PING_INTERVAL" default:"30"` // Интервал проверки соединения в секундах
+	SendBuffer     int      `envconfig:"LIVE_SEND_BUFFER" default:"64"`   // Сколько событий может ждать отправки медленному клиенту, после этого соединение закрывается
+	EventLog       int      `envconfig:"LIVE_EVENT_LOG" default:"1000"`   // Сколько последних событий хранится для переподключения с Last-Event-ID
+}
+
+// Виды событий живых обновлений
+const (
+	LiveNewPost      = "new_post"     // Новый пост в ленте, данные - PostForFeed
+	LiveLikes        = "likes"        // Изменилось количество реакций поста, данные - LiveLikesJson
+	LiveNotification = "notification" // Новое уведомление пользователя, данные - LiveNotificationJson
+	LiveReset        = "reset"        // Пропущенные события уже не хранятся, клиенту нужно заново загрузить данные
+)
+
+// Действия клиента живых обновлений
+const (
+	LiveSubscribe   = "subscribe"   // Следить за реакциями постов
+	LiveUnsubscribe = "unsubscribe" // Перестать следить за реакциями постов
+)
+
+// Событие живых обновлений
+type LiveEvent struct {
+	ID   uint64      `json:"id"`   // Порядковый номер события
+	Type string      `json:"type"` // Вид события
+	Data interface{} `json:"data"`
+}
+
+// Сообщение клиента живых обновлений
+type LiveRequest struct {
+	Action  string `json:"action"`  // subscribe или unsubscribe
+	PostIDs []uint `json:"postIds"` // Посты, которые клиент сейчас показывает
+}
+
+// Изменение количества реакций поста
+type LiveLikesJson struct {
+	PostID    uint           `json:"postId"`
+	Likes     int            `json:"likes"`
+	Reactions map[string]int `json:"reactions"`
+}
+
+// Новое уведомление. Само уведомление клиент получает через get-notifications.
+type LiveNotificationJson struct {
+	ID     uint   `json:"id"`
+	Type   string `json:"type"`
+	PostID uint   `json:"postId"`
+}
@@ -0,0 +1,45 @@
+package model
+
+type MediaConfig struct {
+	Storage      string   `envconfig:"MEDIA_STORAGE" default:"local"`                                           // Хранилище файлов: local или s3
+	LocalDir     string   `envconfig:"MEDIA_LOCAL_DIR" default:"./uploads"`                                     // Каталог файлов для хранилища local
+	MaxSize      int      `envconfig:"MEDIA_MAX_SIZE" default:"10"`                                             // Максимальный размер файла в МБ
+	UserQuota    int      `envconfig:"MEDIA_USER_QUOTA" default:"500"`                                          // Сколько МБ файлов может загрузить пользователь, 0 - без ограничения
+	AllowedTypes []string `envconfig:"MEDIA_ALLOWED_TYPES" default:"image/jpeg,image/png,image/gif,image/webp"` // Разрешенные типы файлов, определяются по содержимому
+	S3Endpoint   string   `envconfig:"MEDIA_S3_ENDPOINT"`                                                       // Адрес S3-совместимого хранилища, например http://localhost:9000
+	S3Region     string   `envconfig:"MEDIA_S3_REGION" default:"us-east-1"`
+	S3Bucket     string   `envconfig:"MEDIA_S3_BUCKET"`
+	S3AccessKey  string   `envconfig:"MEDIA_S3_ACCESS_KEY"`
+	S3SecretKey  string   `envconfig:"MEDIA_S3_SECRET_KEY"`
+	S3PathStyle  bool     `envconfig:"MEDIA_S3_PATH_STYLE" default:"true"` // Адрес файла вида endpoint/bucket/key, иначе bucket.endpoint/key
+	S3PublicURL  string   `envconfig:"MEDIA_S3_PUBLIC_URL"`                // Адрес, по которому файлы доступны читателям, по умолчанию адрес бакета
+
+	Workers        int `envconfig:"MEDIA_WORKERS" default:"2"`          // Количество фоновых обработчиков изображений
+	QueueSize      int `envconfig:"MEDIA_QUEUE_SIZE" default:"1000"`    // Сколько изображений может ждать обработки
+	RescanInterval int `envconfig:"MEDIA_RESCAN_INTERVAL" default:"5"`  // Как часто в минутах искать необработанные изображения, не попавшие в очередь
+	MaxPixels      int `envconfig:"MEDIA_MAX_PIXELS" default:"40"`      // Максимальный размер изображения в мегапикселях
+	ThumbnailSize  int `envconfig:"MEDIA_THUMBNAIL_SIZE" default:"320"` // Длинная сторона миниатюры в пикселях
+	MediumSize     int `envconfig:"MEDIA_MEDIUM_SIZE" default:"960"`    // Длинная сторона среднего варианта в пикселях
+	LargeSize      int `envconfig:"MEDIA_LARGE_SIZE" default:"1920"`    // Длинная сторона большого варианта в пикселях
+	JPEGQuality    int `envconfig:"MEDIA_JPEG_QUALITY" default:"82"`    // Качество вариантов в формате JPEG от 1 до 100
+}
+
+// Хранилища файлов
+const (
+	MediaStorageLocal = "local" // Каталог на диске сервера
+	MediaStorageS3    = "s3"    // S3-совместимое хранилище
+)
+
+// Статусы обработки загруженного изображения
+const (
+	MediaProcessing = "processing" // Ждет фоновой обработки, файл еще не отдается читателям
+	MediaReady      = "ready"      // Метаданные удалены, варианты созданы
+	MediaFailed     = "failed"     // Изображение не удалось обработать
+)
+
+// Варианты изображения
+const (
+	MediaThumbnail = "thumbnail" // Миниатюра для ленты
+	MediaMedium    = "medium"    // Для телефонов и узких колонок
+	MediaLarge     = "large"     // Для широких экранов
+)
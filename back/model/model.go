@@ -1,56 +1,399 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 )
 
 const (
-	AdminRole = "admin"
-	UserRole  = "user"
+	AdminRole     = "admin"
+	UserRole      = "user"
+	ModeratorRole = "moderator"
 )
 
 //nolint:unused
 type User struct {
 	gorm.Model `swagger:"ignore"`
-	Name       string `gorm:"type:varchar(1000);not null"json:"Name"`
-	Email      string `gorm:"type:varchar(1000);not null;unique"json:"email"`
-	Password   string `gorm:"type:varchar(1000);not null"json:"password"`
-	Role       string `gorm:"type:varchar(100);not null"json:"role"`
+	Name       string `gorm:"type:varchar(1000);not null" json:"Name"`
+	Username   string `gorm:"type:varchar(30);uniqueIndex;default:null" json:"username"` // Уникальное имя для упоминаний, у пользователей до появления имен назначается при запуске
+	Email      string `gorm:"type:varchar(1000);not null;unique" json:"email"`
+	Password   string `gorm:"type:varchar(1000);not null" json:"password"`
+	Role       string `gorm:"type:varchar(100);not null" json:"role"`
+	// Время окончания блокировки пользователя модератором, nil - пользователь не заблокирован
+	SuspendedUntil *time.Time `json:"suspended_until"`
 }
 
+// Like реакция пользователя на пост. Лайк - реакция вида ReactionLike.
+// Каждую реакцию пользователь ставит посту не больше одного раза, снятая реакция удаляется полностью.
+// Мягко удаленными бывают только реакции постов из корзины.
+//
 //nolint:unused
 type Like struct {
 	gorm.Model `swagger:"ignore"`
-	UserID     uint `json:"user_id"`                       // ID пользователя, который поставил лайк
-	PostID     uint `json:"post_id"`                       // ID поста, к которому относится лайк
-	Post       Post `gorm:"foreignKey:PostID" json:"post"` // Связь с постом
+	UserID     uint   `gorm:"uniqueIndex:idx_like_user_post_kind" json:"user_id"`                                                                // ID пользователя, который поставил реакцию
+	PostID     uint   `gorm:"index:idx_like_post_kind;uniqueIndex:idx_like_user_post_kind" json:"post_id"`                                       // ID поста, к которому относится реакция
+	Kind       string `gorm:"type:varchar(30);not null;default:'like';index:idx_like_post_kind;uniqueIndex:idx_like_user_post_kind" json:"kind"` // Вид реакции
+	Post       Post   `gorm:"foreignKey:PostID" json:"post"`                                                                                     // Связь с постом
 }
 
+// ReactionLike вид реакции "лайк", количество таких реакций хранится в Post.LikesCount
+const ReactionLike = "like"
+
 //nolint:unused
 type Tag struct {
-	gorm.Model `swagger:"ignore"`
-	Name       string `gorm:"type:varchar(100);not null;unique" json:"name"`
-	Posts      []Post `gorm:"many2many:post_tags;" json:"posts"` // Связь многие-ко-многим с постами
+	gorm.Model  `swagger:"ignore"`
+	Name        string `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Description string `gorm:"type:text;not null;default:''" json:"description"` // Описание тега
+	Posts       []Post `gorm:"many2many:post_tags;" json:"posts"`                // Связь многие-ко-многим с постами
 }
 
 //nolint:unused
 type Post struct {
-	gorm.Model `swagger:"-"`
-	Title      string `gorm:"type:varchar(1000);not null" json:"title"`
-	SubTitle   string `gorm:"type:varchar(1000);not null" json:"subtitle"`
-	Content    string `gorm:"type:text;not null" json:"content"`
-	Tags       []Tag  `gorm:"many2many:post_tags;" json:"tags"` // Связь многие-ко-многим с тегами
-	AuthorID   uint   `json:"author_id"`
-	Likes      []Like `gorm:"foreignKey:PostID" json:"likes"` // Связь с лайками
-	LikesCount int    `json:"likes_count"`                    // Количество лайков
+	gorm.Model    `swagger:"-"`
+	Title         string `gorm:"type:varchar(1000);not null" json:"title"`
+	Slug          string `gorm:"type:varchar(100);index" json:"slug"` // Текущий slug поста, уникальность обеспечивает PostSlug
+	SubTitle      string `gorm:"type:varchar(1000);not null" json:"subtitle"`
+	Content       string `gorm:"type:text;not null" json:"content"`
+	ContentHTML   string `gorm:"type:text;not null;default:''" json:"content_html"` // Отрендеренное и очищенное содержание текущей ревизии
+	Tags          []Tag  `gorm:"many2many:post_tags;" json:"tags"`                  // Связь многие-ко-многим с тегами
+	AuthorID      uint   `json:"author_id"`
+	Likes         []Like `gorm:"foreignKey:PostID" json:"likes"`                                    // Связь с лайками
+	LikesCount    int    `json:"likes_count"`                                                       // Количество лайков
+	Version       uint   `gorm:"not null;default:1" json:"version"`                                 // Версия поста, увеличивается при каждом сохранении
+	Excerpt       string `gorm:"type:text;not null;default:''" json:"excerpt"`                      // Начало текста без разметки для ленты
+	WordCount     int    `gorm:"not null;default:0" json:"word_count"`                              // Количество слов
+	ReadingTime   int    `gorm:"not null;default:0" json:"reading_time"`                            // Время чтения в минутах, 0 - сведения еще не посчитаны
+	TOC           TOC    `gorm:"type:text" json:"toc"`                                              // Оглавление по заголовкам
+	CommentsCount int    `gorm:"not null;default:0" json:"comments_count"`                          // Количество опубликованных комментариев
+	CommentMode   string `gorm:"type:varchar(20);not null;default:'open'" json:"comment_mode"`      // Режим комментариев: open, closed или approval
+	Status        string `gorm:"type:varchar(20);not null;default:'published';index" json:"status"` // published, pending или hidden
+	Fingerprint   string `gorm:"type:varchar(64);not null;default:'';index" json:"fingerprint"`     // Отпечаток текста для поиска повторов
+	CoverMediaID  uint   `gorm:"not null;default:0" json:"cover_media_id"`                          // Первое изображение из текста, 0 - изображений нет
+}
+
+// Статусы постов
+const (
+	PostPublished = "published" // Опубликован
+	PostPending   = "pending"   // Задержан фильтром до проверки модератором, виден только автору и модераторам
+	PostHidden    = "hidden"    // Скрыт модератором, виден только автору и модераторам
+)
+
+// Режимы комментариев к посту
+const (
+	CommentsOpen     = "open"     // Комментарии публикуются сразу
+	CommentsClosed   = "closed"   // Новые комментарии запрещены
+	CommentsApproval = "approval" // Комментарии публикуются после одобрения автором поста или модератором
+)
+
+// TOCEntry элемент оглавления поста
+type TOCEntry struct {
+	Level  int    `json:"level"`  // Уровень заголовка от 1 до 6
+	Text   string `json:"text"`   // Текст заголовка
+	Anchor string `json:"anchor"` // Якорь заголовка в отрендеренном HTML
+}
+
+// TOC оглавление поста, хранится в БД как JSON
+type TOC []TOCEntry
+
+func (t TOC) Value() (driver.Value, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *TOC) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case []byte:
+		return json.Unmarshal(v, t)
+	default:
+		return fmt.Errorf("неподдерживаемый тип оглавления: %T", value)
+	}
 }
 
 type ProfileRequest struct {
-	ID int `json:"id"`
+	ID       int    `json:"id"`
+	Username string `json:"username"` // Используется, если ID не указан
 }
 
 type ProfileResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message,omitempty"`
-	Name    string `json:"name"`
+	Status    bool   `json:"status"`
+	Message   string `json:"message,omitempty"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Followers int64  `json:"followers"` // Количество подписчиков
+	Following int64  `json:"following"` // Количество авторов, на которых подписан пользователь
+	Followed  bool   `json:"followed"`  // Владелец токена подписан на пользователя
+	Blocked   bool   `json:"blocked"`   // Владелец токена заблокировал пользователя
+	Muted     bool   `json:"muted"`     // Владелец токена скрыл пользователя
+}
+
+// PostRevision хранит полный снимок поста на момент сохранения
+//
+//nolint:unused
+type PostRevision struct {
+	gorm.Model   `swagger:"-"`
+	PostID       uint     `gorm:"not null;uniqueIndex:idx_post_revision_number" json:"post_id"`
+	Number       uint     `gorm:"not null;uniqueIndex:idx_post_revision_number" json:"number"` // Порядковый номер ревизии в рамках поста
+	EditorID     uint     `gorm:"not null" json:"editor_id"`                                   // ID пользователя, сохранившего ревизию
+	ByAdmin      bool     `gorm:"not null;default:false" json:"by_admin"`                      // Ревизия сохранена администратором в чужом посте
+	RestoredFrom uint     `gorm:"not null;default:0" json:"restored_from"`                     // Номер восстановленной ревизии, 0 - обычное сохранение
+	Title        string   `gorm:"type:varchar(1000);not null" json:"title"`
+	SubTitle     string   `gorm:"type:varchar(1000);not null" json:"subtitle"`
+	Content      string   `gorm:"type:text;not null" json:"content"`
+	ContentHTML  string   `gorm:"type:text;not null;default:''" json:"content_html"` // Отрендеренное содержание, рендерится один раз на ревизию
+	Tags         []string `gorm:"type:text;serializer:json" json:"tags"`
+}
+
+// PostSlug хранит все slug поста, включая прежние, чтобы старые ссылки вели на пост после смены заголовка
+//
+//nolint:unused
+type PostSlug struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+	PostID    uint      `gorm:"not null;index" json:"post_id"`
+}
+
+// TagSubscription подписка пользователя на тег
+//
+//nolint:unused
+type TagSubscription struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tag_subscription" json:"user_id"`
+	TagID     uint      `gorm:"not null;uniqueIndex:idx_tag_subscription;index" json:"tag_id"`
+}
+
+// Comment комментарий к посту. Ответы на комментарии образуют дерево.
+//
+//nolint:unused
+type Comment struct {
+	gorm.Model  `swagger:"ignore"`
+	PostID      uint       `gorm:"not null;index" json:"post_id"`
+	AuthorID    uint       `gorm:"not null;index" json:"author_id"`
+	ParentID    uint       `gorm:"not null;default:0;index" json:"parent_id"` // ID комментария, на который это ответ, 0 - комментарий к посту
+	RootID      uint       `gorm:"not null;default:0;index" json:"root_id"`   // ID комментария верхнего уровня в ветке, 0 - комментарий верхнего уровня
+	Body        string     `gorm:"type:text;not null" json:"body"`            // Исходный Markdown
+	BodyHTML    string     `gorm:"type:text;not null;default:''" json:"body_html"`
+	EditedAt    *time.Time `json:"edited_at"`                                                        // Время последнего изменения, nil - комментарий не изменялся
+	Status      string     `gorm:"type:varchar(20);not null;default:'approved';index" json:"status"` // approved, pending, held, rejected или hidden
+	Locked      bool       `gorm:"not null;default:false" json:"locked"`                             // Ветка закрыта для ответов, только для комментариев верхнего уровня
+	Fingerprint string     `gorm:"type:varchar(64);not null;default:'';index" json:"fingerprint"`    // Отпечаток текста для поиска повторов
+}
+
+// Статусы комментариев
+const (
+	CommentApproved = "approved" // Опубликован
+	CommentPending  = "pending"  // Ожидает одобрения
+	CommentHeld     = "held"     // Задержан фильтром, публикуется только по решению модератора по жалобе
+	CommentRejected = "rejected" // Отклонен
+	CommentHidden   = "hidden"   // Скрыт модератором по жалобе
+)
+
+// CommentBan запрет пользователю комментировать посты автора
+//
+//nolint:unused
+type CommentBan struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	AuthorID  uint      `gorm:"not null;uniqueIndex:idx_comment_ban" json:"author_id"` // Автор постов
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_comment_ban" json:"user_id"`   // Пользователь, которому запрещено комментировать
+}
+
+// Типы уведомлений
+const (
+	NotificationComment = "comment" // Новый комментарий к посту
+	NotificationFollow  = "follow"  // Новый подписчик
+	NotificationLike    = "like"    // Новый лайк поста, лайки за короткое время собираются в одно уведомление
+	NotificationMention = "mention" // Упоминание пользователя
+	NotificationReport  = "report"  // Жалоба пользователя рассмотрена
+)
+
+// Notification уведомление пользователя о событии. Уведомления о лайках одного поста собираются в группу:
+// пользователю показывается первое уведомление группы, остальные хранят, кто еще поставил лайк.
+//
+//nolint:unused
+type Notification struct {
+	gorm.Model `swagger:"ignore"`
+	UserID     uint   `gorm:"not null;index" json:"user_id"`            // Получатель уведомления
+	GroupID    uint   `gorm:"not null;default:0;index" json:"group_id"` // ID первого уведомления группы, 0 - уведомление показывается пользователю
+	ActorID    uint   `gorm:"not null" json:"actor_id"`                 // Пользователь, вызвавший событие
+	Type       string `gorm:"type:varchar(50);not null" json:"type"`
+	PostID     uint   `gorm:"not null;default:0;index" json:"post_id"`
+	CommentID  uint   `gorm:"not null;default:0" json:"comment_id"`
+	ReportID   uint   `gorm:"not null;default:0" json:"report_id"` // Рассмотренная жалоба для уведомлений о жалобах
+	Read       bool   `gorm:"not null;default:false" json:"read"`
+}
+
+// ReadingList именованный список закладок пользователя
+//
+//nolint:unused
+type ReadingList struct {
+	gorm.Model `swagger:"ignore"`
+	UserID     uint   `gorm:"not null;index" json:"user_id"`
+	Name       string `gorm:"type:varchar(100);not null" json:"name"`
+	Public     bool   `gorm:"not null;default:false" json:"public"` // Публичный список могут смотреть другие пользователи
+}
+
+// Bookmark закладка пользователя на пост. Закладка без списка лежит в списке "Сохраненное".
+// Закладка удаленного поста остается и показывается как удаленная.
+//
+//nolint:unused
+type Bookmark struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_bookmark" json:"user_id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_bookmark;index" json:"post_id"`
+	ListID    uint      `gorm:"not null;default:0;index" json:"list_id"`                  // ID списка, 0 - список "Сохраненное"
+	Position  int       `gorm:"not null;default:0" json:"position"`                       // Порядок закладки в списке
+	PostTitle string    `gorm:"type:varchar(1000);not null;default:''" json:"post_title"` // Заголовок поста на момент добавления, показывается для удаленного поста
+}
+
+// Follow подписка пользователя на автора
+//
+//nolint:unused
+type Follow struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	FollowerID uint      `gorm:"not null;uniqueIndex:idx_follow" json:"follower_id"`       // Подписчик
+	FolloweeID uint      `gorm:"not null;uniqueIndex:idx_follow;index" json:"followee_id"` // Автор
+}
+
+// Mention упоминание пользователя в посте или комментарии. Упоминания соответствуют текущему тексту,
+// а об упоминании пользователь узнает один раз: уведомления хранятся отдельно.
+//
+//nolint:unused
+type Mention struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_mention" json:"post_id"`
+	CommentID uint      `gorm:"not null;default:0;uniqueIndex:idx_mention" json:"comment_id"` // 0 - упоминание в тексте поста
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_mention;index" json:"user_id"`        // Упомянутый пользователь
+}
+
+// Block блокировка пользователя. Заблокированный пользователь не может ставить реакции, комментировать,
+// упоминать заблокировавшего и подписываться на него, а посты друг друга не видит ни один из них.
+//
+//nolint:unused
+type Block struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	BlockerID uint      `gorm:"not null;uniqueIndex:idx_block" json:"blocker_id"`       // Заблокировавший пользователь
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_block;index" json:"blocked_id"` // Заблокированный пользователь
+}
+
+// Mute скрытие пользователя: его посты не показываются в лентах скрывшего. Скрытый пользователь об этом не узнает.
+//
+//nolint:unused
+type Mute struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	MuterID   uint      `gorm:"not null;uniqueIndex:idx_mute" json:"muter_id"` // Скрывший пользователь
+	MutedID   uint      `gorm:"not null;uniqueIndex:idx_mute" json:"muted_id"` // Скрытый пользователь
+}
+
+// Report жалоба на пост, комментарий или пользователя в очереди модерации. Пока жалоба не рассмотрена,
+// повторные жалобы на тот же объект добавляются к ней, поэтому каждый объект попадает в очередь один раз.
+//
+//nolint:unused
+type Report struct {
+	gorm.Model     `swagger:"ignore"`
+	TargetType     string     `gorm:"type:varchar(20);not null;index:idx_report_target" json:"target_type"` // post, comment или user
+	TargetID       uint       `gorm:"not null;index:idx_report_target" json:"target_id"`
+	TargetAuthorID uint       `gorm:"not null;default:0" json:"target_author_id"`                   // Автор поста или комментария, для жалобы на пользователя - сам пользователь
+	PostID         uint       `gorm:"not null;default:0" json:"post_id"`                            // Пост, к которому относится объект жалобы
+	Status         string     `gorm:"type:varchar(20);not null;default:'open';index" json:"status"` // open, dismissed или resolved
+	ReportsCount   int        `gorm:"not null;default:0" json:"reports_count"`                      // Количество пользователей, пожаловавшихся на объект
+	Held           bool       `gorm:"not null;default:false" json:"held"`                           // Объект задержан фильтром и будет опубликован, если жалобу отклонят
+	AssigneeID     uint       `gorm:"not null;default:0;index" json:"assignee_id"`                  // Модератор, рассматривающий жалобу, 0 - не назначен
+	Action         string     `gorm:"type:varchar(20);not null;default:''" json:"action"`           // Принятое решение
+	ResolverID     uint       `gorm:"not null;default:0" json:"resolver_id"`                        // Модератор, принявший решение
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+// ReportEntry жалоба одного пользователя. Повторная жалоба того же пользователя заменяет причину и пояснение.
+//
+//nolint:unused
+type ReportEntry struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ReportID   uint      `gorm:"not null;uniqueIndex:idx_report_entry" json:"report_id"`
+	ReporterID uint      `gorm:"not null;uniqueIndex:idx_report_entry" json:"reporter_id"`
+	Reason     string    `gorm:"type:varchar(30);not null" json:"reason"`
+	Text       string    `gorm:"type:text;not null;default:''" json:"text"` // Пояснение пользователя
+}
+
+// FilterRules правила фильтра нового содержимого, измененные администратором. Хранится одна запись;
+// пока ее нет, действуют правила из конфигурации.
+//
+//nolint:unused
+type FilterRules struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	UpdatedBy          uint      `gorm:"not null;default:0" json:"updated_by"` // Администратор, изменивший правила
+	MaxLinks           int       `gorm:"not null;default:0" json:"max_links"`
+	MaxLinkDensity     int       `gorm:"not null;default:0" json:"max_link_density"`
+	BannedWords        []string  `gorm:"type:text;serializer:json" json:"banned_words"`
+	BannedPatterns     []string  `gorm:"type:text;serializer:json" json:"banned_patterns"`
+	DuplicateWindow    int       `gorm:"not null;default:0" json:"duplicate_window"`
+	DuplicateMinLength int       `gorm:"not null;default:0" json:"duplicate_min_length"`
+	PostsPerHour       int       `gorm:"not null;default:0" json:"posts_per_hour"`
+	CommentsPerHour    int       `gorm:"not null;default:0" json:"comments_per_hour"`
+	ProbationDays      int       `gorm:"not null;default:0" json:"probation_days"`
+	ProbationMaxLinks  int       `gorm:"not null;default:0" json:"probation_max_links"`
+}
+
+// Media файл, загруженный пользователем. Сам файл лежит в хранилище под именем Key.
+//
+//nolint:unused
+type Media struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	OwnerID     uint      `gorm:"not null;index" json:"owner_id"`
+	Key         string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"key"`                  // Имя файла в хранилище
+	Name        string    `gorm:"type:varchar(255);not null;default:''" json:"name"`                  // Имя файла у пользователя
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`                     // Тип, определенный по содержимому
+	Size        int64     `gorm:"not null" json:"size"`                                               // Размер в байтах
+	Hash        string    `gorm:"type:varchar(64);not null;default:'';index" json:"hash"`             // SHA-256 содержимого для поиска повторной загрузки
+	Status      string    `gorm:"type:varchar(20);not null;default:'processing';index" json:"status"` // processing, ready или failed
+	Width       int       `gorm:"not null;default:0" json:"width"`
+	Height      int       `gorm:"not null;default:0" json:"height"`
+	Blurhash    string    `gorm:"type:varchar(100);not null;default:''" json:"blurhash"` // Размытая заглушка, которую показывают до загрузки изображения
+}
+
+// MediaVariant уменьшенная копия загруженного изображения без метаданных
+//
+//nolint:unused
+type MediaVariant struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	MediaID   uint      `gorm:"not null;index" json:"media_id"`
+	Name      string    `gorm:"type:varchar(20);not null" json:"name"`             // thumbnail, medium или large
+	Format    string    `gorm:"type:varchar(10);not null" json:"format"`           // jpeg, png или webp
+	Key       string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"key"` // Имя файла в хранилище
+	Width     int       `gorm:"not null" json:"width"`
+	Height    int       `gorm:"not null" json:"height"`
+	Size      int64     `gorm:"not null" json:"size"`
+}
+
+// PostMedia ссылка поста на загруженный файл. Файл нельзя удалить, пока на него ссылается хотя бы один пост.
+//
+//nolint:unused
+type PostMedia struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_post_media" json:"post_id"`
+	MediaID   uint      `gorm:"not null;uniqueIndex:idx_post_media;index" json:"media_id"`
 }
@@ -0,0 +1,5 @@
+package model
+
+type NotificationConfig struct {
+	GroupWindow int `envconfig:"NOTIFICATION_GROUP_WINDOW" default:"60"` // Время, в течение которого лайки одного поста собираются в одно уведомление, в минутах
+}
@@ -0,0 +1,6 @@
+package model
+
+type PostConfig struct {
+	ExcerptLength int `envconfig:"POST_EXCERPT_LENGTH" default:"300"` // Длина отрывка для ленты в символах
+	ReadingSpeed  int `envconfig:"POST_READING_SPEED" default:"200"`  // Скорость чтения в словах в минуту
+}
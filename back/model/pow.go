@@ -0,0 +1,23 @@
+package model
+
+type PowConfig struct {
+	Enabled        bool   `envconfig:"POW_ENABLED" default:"false"`      // Требовать решение задачи перед отправкой писем
+	Secret         string `envconfig:"POW_SECRET"`                       // Ключ подписи задач, по умолчанию JWT_TOKEN_PASSWORD
+	ChallengeTTL   int    `envconfig:"POW_CHALLENGE_TTL" default:"5"`    // Время жизни задачи в минутах
+	BaseDifficulty int    `envconfig:"POW_BASE_DIFFICULTY" default:"18"` // Сложность задачи без нагрузки в нулевых битах хеша
+	MaxDifficulty  int    `envconfig:"POW_MAX_DIFFICULTY" default:"24"`  // Максимальная сложность задачи
+	RateWindow     int    `envconfig:"POW_RATE_WINDOW" default:"1"`      // Окно подсчета выданных задач в минутах
+	RateStep       int    `envconfig:"POW_RATE_STEP" default:"20"`       // Количество задач за окно, после которого сложность растет на бит
+}
+
+// Назначения задач
+const (
+	PowRegister      = "register"       // Регистрация
+	PowResetPassword = "reset-password" // Сброс пароля
+)
+
+// ProofOfWork решение задачи, которое клиент передает вместе с запросом, отправляющим письмо
+type ProofOfWork struct {
+	Challenge string `json:"powChallenge"` // Задача, полученная от /api/pow-challenge
+	Solution  string `json:"powSolution"`  // Найденное решение
+}
@@ -0,0 +1,30 @@
+package model
+
+type RateLimitConfig struct {
+	Enabled        bool     `envconfig:"RATE_LIMIT_ENABLED" default:"true"`                   // Ограничивать частоту запросов
+	AuthRate       int      `envconfig:"RATE_LIMIT_AUTH_RATE" default:"10"`                   // Запросов авторизации в минуту, 0 - без ограничения
+	AuthBurst      int      `envconfig:"RATE_LIMIT_AUTH_BURST" default:"5"`                   // Сколько запросов авторизации можно сделать подряд
+	AuthKey        string   `envconfig:"RATE_LIMIT_AUTH_KEY" default:"ip"`                    // По чему считать запросы авторизации: ip, user или both
+	WriteRate      int      `envconfig:"RATE_LIMIT_WRITE_RATE" default:"60"`                  // Изменяющих запросов в минуту, 0 - без ограничения
+	WriteBurst     int      `envconfig:"RATE_LIMIT_WRITE_BURST" default:"30"`                 // Сколько изменяющих запросов можно сделать подряд
+	WriteKey       string   `envconfig:"RATE_LIMIT_WRITE_KEY" default:"both"`                 // По чему считать изменяющие запросы: ip, user или both
+	ReadRate       int      `envconfig:"RATE_LIMIT_READ_RATE" default:"600"`                  // Читающих запросов в минуту, 0 - без ограничения
+	ReadBurst      int      `envconfig:"RATE_LIMIT_READ_BURST" default:"120"`                 // Сколько читающих запросов можно сделать подряд
+	ReadKey        string   `envconfig:"RATE_LIMIT_READ_KEY" default:"both"`                  // По чему считать читающие запросы: ip, user или both
+	AllowList      []string `envconfig:"RATE_LIMIT_ALLOW_LIST" default:"127.0.0.0/8,::1/128"` // Адреса и подсети без ограничений
+	TrustedProxies []string `envconfig:"RATE_LIMIT_TRUSTED_PROXIES"`                          // Адреса и подсети прокси, от которых принимается X-Forwarded-For
+}
+
+// Группы маршрутов с общими ограничениями
+const (
+	RateLimitAuth  = "auth"  // Регистрация, вход и выдача задач proof-of-work
+	RateLimitWrite = "write" // Запросы, изменяющие данные
+	RateLimitRead  = "read"  // Запросы, только читающие данные
+)
+
+// По чему считаются запросы группы
+const (
+	RateLimitByIP   = "ip"   // По адресу клиента
+	RateLimitByUser = "user" // По пользователю, для неавторизованных запросов - по адресу
+	RateLimitByBoth = "both" // И по адресу, и по пользователю: запрос проходит, если не превышено ни одно ограничение
+)
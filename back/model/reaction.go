@@ -0,0 +1,5 @@
+package model
+
+type ReactionConfig struct {
+	Kinds []string `envconfig:"REACTION_KINDS" default:"like,insightful,funny,love"` // Доступные виды реакций, like обязателен
+}
@@ -0,0 +1,45 @@
+package model
+
+type ReportConfig struct {
+	TextMaxLength int `envconfig:"REPORT_TEXT_MAX_LENGTH" default:"1000"` // Максимальная длина пояснения к жалобе в символах
+	SuspendDays   int `envconfig:"REPORT_SUSPEND_DAYS" default:"7"`       // Срок блокировки автора по умолчанию в днях
+}
+
+// Объекты жалоб
+const (
+	ReportPost    = "post"
+	ReportComment = "comment"
+	ReportUser    = "user"
+)
+
+// Причины жалоб
+const (
+	ReasonSpam           = "spam"           // Спам или реклама
+	ReasonHarassment     = "harassment"     // Оскорбления и травля
+	ReasonHate           = "hate"           // Разжигание ненависти
+	ReasonViolence       = "violence"       // Насилие или угрозы
+	ReasonSexual         = "sexual"         // Материалы сексуального характера
+	ReasonMisinformation = "misinformation" // Ложная информация
+	ReasonOther          = "other"          // Другое, причина описывается в пояснении
+	ReasonFilter         = "filter"         // Содержимое задержано фильтром, пользователи эту причину не выбирают
+)
+
+// ReportReasons допустимые причины жалоб в порядке показа
+var ReportReasons = []string{
+	ReasonSpam, ReasonHarassment, ReasonHate, ReasonViolence, ReasonSexual, ReasonMisinformation, ReasonOther,
+}
+
+// Статусы жалоб
+const (
+	ReportOpen      = "open"      // Ожидает рассмотрения
+	ReportDismissed = "dismissed" // Нарушений не найдено
+	ReportResolved  = "resolved"  // Приняты меры
+)
+
+// Решения по жалобам
+const (
+	ReportActionDismiss = "dismiss" // Отклонить жалобу
+	ReportActionHide    = "hide"    // Скрыть пост или комментарий
+	ReportActionDelete  = "delete"  // Удалить пост или комментарий
+	ReportActionSuspend = "suspend" // Заблокировать автора
+)
@@ -0,0 +1,7 @@
+package model
+
+type TagConfig struct {
+	MaxPerPost int      `envconfig:"TAG_MAX_PER_POST" default:"10"`                // Максимальное количество тегов у поста
+	MaxLength  int      `envconfig:"TAG_MAX_LENGTH" default:"50"`                  // Максимальная длина тега в символах
+	Reserved   []string `envconfig:"TAG_RESERVED" default:"official,announcement"` // Теги, которые может ставить только администратор
+}
@@ -0,0 +1,6 @@
+package model
+
+type TrashConfig struct {
+	RetentionDays int `envconfig:"TRASH_RETENTION_DAYS" default:"30"` // Срок хранения удаленных постов в днях
+	PurgeInterval int `envconfig:"TRASH_PURGE_INTERVAL" default:"60"` // Интервал очистки корзины в минутах
+}
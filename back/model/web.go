@@ -35,6 +35,7 @@ type RegisterRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
+	ProofOfWork
 }
 
 type CodeRequest struct {
@@ -54,6 +55,11 @@ type EmailPasswordRequest struct {
 	Password string `json:"password"`
 }
 
+type ResetPasswordRequest struct {
+	Email string `json:"email"`
+	ProofOfWork
+}
+
 type NewPostRequest struct {
 	Title    string   `json:"title"`
 	SubTitle string   `json:"subtitle"`
@@ -67,11 +73,22 @@ type NewPostResponse struct {
 }
 
 type PostJson struct {
-	ID       uint     `json:"id"`
-	Title    string   `json:"title"`
-	SubTitle string   `json:"subtitle"`
-	Content  string   `json:"content"`
-	Tags     []string `json:"tags"`
+	ID          uint           `json:"id"`
+	Slug        string         `json:"slug"` // Slug поста, при сохранении игнорируется
+	URL         string         `json:"url"`  // Постоянная ссылка на пост, при сохранении игнорируется
+	Title       string         `json:"title"`
+	SubTitle    string         `json:"subtitle"`
+	Content     string         `json:"content"`     // Исходный Markdown
+	ContentHTML string         `json:"contentHtml"` // Отрендеренный HTML, при сохранении игнорируется
+	WordCount   int            `json:"wordCount"`   // Количество слов, при сохранении игнорируется
+	ReadingTime int            `json:"readingTime"` // Время чтения в минутах, при сохранении игнорируется
+	TOC         TOC            `json:"toc"`         // Оглавление, при сохранении игнорируется
+	Tags        []string       `json:"tags"`
+	Version     uint           `json:"version"`     // Версия поста. При обновлении 0 отключает проверку версии
+	CommentMode string         `json:"commentMode"` // Режим комментариев, при сохранении игнорируется
+	Status      string         `json:"status"`      // Статус поста: published, pending или hidden, при сохранении игнорируется
+	Reactions   map[string]int `json:"reactions"`   // Количество реакций по видам, при сохранении игнорируется
+	MyReactions []string       `json:"myReactions"` // Реакции текущего пользователя, при сохранении игнорируется
 }
 
 type GetPostRequest struct {
@@ -99,25 +116,43 @@ type UpdatePostRequest struct {
 
 type UpdatePostResponse struct {
 	Response
+	Version uint `json:"version"` // Новая версия поста
+}
+
+// Ответ на обновление поста устаревшей версии
+type UpdatePostConflictResponse struct {
+	Response
+	Post PostJson `json:"post"` // Актуальная версия поста на сервере
 }
 
 // Пост для списка постов
 type PostForFeed struct {
-	Title        string   `json:"title"`
-	SubTitle     string   `json:"subtitle"`
-	AuthorName   string   `json:"authorName"`   // Имя автора
-	Likes        int      `json:"likes"`        // Количество лайков
-	Content      string   `json:"content"`      // Содержание статьи
-	Tags         []string `json:"tags"`         // Теги статьи. Собрать и переделать в слайс
-	ID           uint     `json:"id"`           // ID статьи
-	AuthorId     uint     `json:"authorId"`     // ID автора
-	InitialLiked bool     `json:"initialLiked"` // Лайкнул ли пользователь статью ID из запроса
-	Date         string   `json:"date"`         // Дата публикации
+	Title       string         `json:"title"`
+	SubTitle    string         `json:"subtitle"`
+	AuthorName  string         `json:"authorName"`  // Имя автора
+	Likes       int            `json:"likes"`       // Количество лайков
+	Comments    int            `json:"comments"`    // Количество комментариев
+	Excerpt     string         `json:"excerpt"`     // Отрывок статьи без разметки
+	WordCount   int            `json:"wordCount"`   // Количество слов
+	ReadingTime int            `json:"readingTime"` // Время чтения в минутах
+	Tags        []string       `json:"tags"`        // Теги статьи. Собрать и переделать в слайс
+	ID          uint           `json:"id"`          // ID статьи
+	Slug        string         `json:"slug"`        // Slug статьи
+	URL         string         `json:"url"`         // Постоянная ссылка на статью
+	AuthorId    uint           `json:"authorId"`    // ID автора
+	Reactions   map[string]int `json:"reactions"`   // Количество реакций по видам
+	MyReactions []string       `json:"myReactions"` // Реакции пользователя ID из запроса
+	Bookmarked  bool           `json:"bookmarked"`  // Пост в закладках пользователя ID из запроса
+	Status      string         `json:"status"`      // Статус поста: published, pending или hidden
+	Cover       *ImageJson     `json:"cover"`       // Обложка - первое изображение из текста, null - изображений нет
+	Date        string         `json:"date"`        // Дата публикации
 }
 
 // Запрос на получение всех постов
 type GetAllPostsRequest struct {
-	ID uint `json:"id"` // ID автора
+	ID    uint `json:"id"`    // ID автора для /api/get-all-my-posts, 0 - пользователь из токена. Лента всех постов его не учитывает
+	Page  int  `json:"page"`  // Номер страницы ленты всех постов, начиная с 1
+	Limit int  `json:"limit"` // Количество постов на странице ленты всех постов
 }
 
 // Ответ на запрос получения всех постов
@@ -143,3 +178,730 @@ type SetPasswordRequest struct {
 type SetPasswordResponse struct {
 	Response
 }
+
+// Запрос на получение списка ревизий поста
+type GetPostRevisionsRequest struct {
+	PostID uint `json:"postId"`
+}
+
+// Ревизия поста для списка ревизий
+type PostRevisionJson struct {
+	Number       uint     `json:"number"`       // Номер ревизии
+	EditorID     uint     `json:"editorId"`     // ID пользователя, сохранившего ревизию
+	EditorName   string   `json:"editorName"`   // Имя пользователя, сохранившего ревизию
+	ByAdmin      bool     `json:"byAdmin"`      // Правка администратора в чужом посте
+	RestoredFrom uint     `json:"restoredFrom"` // Номер восстановленной ревизии, 0 - обычное сохранение
+	Title        string   `json:"title"`
+	SubTitle     string   `json:"subtitle"`
+	Tags         []string `json:"tags"`
+	Date         string   `json:"date"` // Дата и время сохранения
+}
+
+type GetPostRevisionsResponse struct {
+	Response
+	Revisions []PostRevisionJson `json:"revisions"`
+}
+
+// Строка построчного сравнения ревизий
+type DiffLine struct {
+	Op   string `json:"op"` // equal, insert или delete
+	Text string `json:"text"`
+}
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// Запрос на сравнение двух ревизий поста
+type GetRevisionDiffRequest struct {
+	PostID uint `json:"postId"`
+	From   uint `json:"from"` // Номер исходной ревизии
+	To     uint `json:"to"`   // Номер конечной ревизии
+}
+
+type GetRevisionDiffResponse struct {
+	Response
+	From     PostRevisionJson `json:"from"`
+	To       PostRevisionJson `json:"to"`
+	Title    []DiffLine       `json:"title"`
+	SubTitle []DiffLine       `json:"subtitle"`
+	Content  []DiffLine       `json:"content"`
+	Tags     []DiffLine       `json:"tags"`
+}
+
+// Запрос на восстановление ревизии поста
+type RestoreRevisionRequest struct {
+	PostID uint `json:"postId"`
+	Number uint `json:"number"` // Номер восстанавливаемой ревизии
+}
+
+type RestoreRevisionResponse struct {
+	Response
+	Number  uint `json:"number"`  // Номер созданной ревизии
+	Version uint `json:"version"` // Новая версия поста
+}
+
+// Удаленный пост в корзине
+type TrashPost struct {
+	ID         uint   `json:"id"`
+	Title      string `json:"title"`
+	SubTitle   string `json:"subtitle"`
+	AuthorId   uint   `json:"authorId"`
+	AuthorName string `json:"authorName"`
+	DeletedAt  string `json:"deletedAt"` // Дата удаления
+	PurgeAt    string `json:"purgeAt"`   // Дата окончательного удаления
+}
+
+type GetTrashResponse struct {
+	Response
+	Posts []TrashPost `json:"posts"`
+}
+
+// Запрос на восстановление поста из корзины
+type RestorePostRequest struct {
+	ID uint `json:"id"`
+}
+
+type RestorePostResponse struct {
+	Response
+}
+
+// Тег со статистикой для списка тегов
+type TagJson struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Posts       int    `json:"posts"` // Количество постов с тегом
+}
+
+type GetTagsResponse struct {
+	Response
+	Tags []TagJson `json:"tags"`
+}
+
+// Запрос на переименование тега и изменение его описания
+type UpdateTagRequest struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateTagResponse struct {
+	Response
+	Tag TagJson `json:"tag"`
+}
+
+// Запрос на объединение тегов: посты тегов FromIDs переносятся в тег ToID, теги FromIDs удаляются
+type MergeTagsRequest struct {
+	FromIDs []uint `json:"fromIds"`
+	ToID    uint   `json:"toId"`
+}
+
+type MergeTagsResponse struct {
+	Response
+	Tag TagJson `json:"tag"`
+}
+
+type DeleteUnusedTagsResponse struct {
+	Response
+	Deleted int `json:"deleted"` // Количество удаленных тегов
+}
+
+// Сортировка ленты постов
+const (
+	SortByDate  = "date"  // Сначала новые
+	SortByLikes = "likes" // Сначала популярные
+)
+
+// Запрос на получение постов тега
+type GetTagPostsRequest struct {
+	TagID uint   `json:"tagId"`
+	Sort  string `json:"sort"`  // date или likes, по умолчанию date
+	Page  int    `json:"page"`  // Номер страницы, начиная с 1
+	Limit int    `json:"limit"` // Количество постов на странице
+}
+
+type GetTagPostsResponse struct {
+	Response
+	Tag       TagJson       `json:"tag"`
+	Following bool          `json:"following"` // Подписан ли пользователь на тег
+	Posts     []PostForFeed `json:"posts"`
+	Page      int           `json:"page"`
+	Total     int64         `json:"total"` // Общее количество постов
+}
+
+// Запрос на получение ленты постов по подпискам на теги
+type GetFollowedTagsFeedRequest struct {
+	Sort  string `json:"sort"`  // date или likes, по умолчанию date
+	Page  int    `json:"page"`  // Номер страницы, начиная с 1
+	Limit int    `json:"limit"` // Количество постов на странице
+}
+
+type GetFollowedTagsFeedResponse struct {
+	Response
+	Posts []PostForFeed `json:"posts"`
+	Page  int           `json:"page"`
+	Total int64         `json:"total"` // Общее количество постов
+}
+
+// Запрос на подписку на тег или отписку от него
+type FollowTagRequest struct {
+	TagID uint `json:"tagId"`
+}
+
+type FollowTagResponse struct {
+	Response
+}
+
+type GetFollowedTagsResponse struct {
+	Response
+	Tags []TagJson `json:"tags"`
+}
+
+// Запрос на создание комментария
+type NewCommentRequest struct {
+	PostID   uint   `json:"postId"`
+	ParentID uint   `json:"parentId"` // ID комментария, на который это ответ, 0 - комментарий к посту
+	Body     string `json:"body"`     // Markdown
+}
+
+// Комментарий в дереве комментариев
+type CommentJson struct {
+	ID         uint          `json:"id"`
+	ParentID   uint          `json:"parentId"`
+	AuthorID   uint          `json:"authorId"`
+	AuthorName string        `json:"authorName"`
+	Body       string        `json:"body"`     // Исходный Markdown, пусто для удаленного комментария
+	BodyHTML   string        `json:"bodyHtml"` // Отрендеренный HTML, пусто для удаленного комментария
+	Date       string        `json:"date"`     // Дата и время создания
+	Edited     bool          `json:"edited"`   // Комментарий изменялся
+	Deleted    bool          `json:"deleted"`  // Комментарий удален или скрыт, но на него есть ответы
+	Pending    bool          `json:"pending"`  // Комментарий ожидает одобрения или проверки модератором
+	Locked     bool          `json:"locked"`   // Ветка закрыта для ответов
+	CanEdit    bool          `json:"canEdit"`
+	CanDelete  bool          `json:"canDelete"`
+	Replies    []CommentJson `json:"replies"`
+}
+
+type NewCommentResponse struct {
+	Response
+	Comment CommentJson `json:"comment"`
+}
+
+// Запрос на изменение комментария
+type UpdateCommentRequest struct {
+	ID   uint   `json:"id"`
+	Body string `json:"body"`
+}
+
+type UpdateCommentResponse struct {
+	Response
+	Comment CommentJson `json:"comment"`
+}
+
+type DeleteCommentRequest struct {
+	ID uint `json:"id"`
+}
+
+type DeleteCommentResponse struct {
+	Response
+}
+
+// Запрос на получение комментариев поста. Страницы считаются по комментариям верхнего уровня.
+type GetCommentsRequest struct {
+	PostID uint `json:"postId"`
+	Page   int  `json:"page"`  // Номер страницы, начиная с 1
+	Limit  int  `json:"limit"` // Количество комментариев верхнего уровня на странице
+}
+
+type GetCommentsResponse struct {
+	Response
+	Comments []CommentJson `json:"comments"`
+	Page     int           `json:"page"`
+	Total    int64         `json:"total"` // Общее количество веток комментариев
+}
+
+// Запрос на изменение режима комментариев поста
+type SetCommentModeRequest struct {
+	PostID uint   `json:"postId"`
+	Mode   string `json:"mode"` // open, closed или approval
+}
+
+type SetCommentModeResponse struct {
+	Response
+}
+
+// Запрос на получение очереди комментариев, ожидающих одобрения
+type GetCommentQueueRequest struct {
+	PostID uint `json:"postId"` // 0 - все посты, доступные для модерации
+	Page   int  `json:"page"`
+	Limit  int  `json:"limit"`
+}
+
+// Комментарий в очереди модерации
+type PendingCommentJson struct {
+	PostID    uint        `json:"postId"`
+	PostTitle string      `json:"postTitle"`
+	Comment   CommentJson `json:"comment"`
+}
+
+type GetCommentQueueResponse struct {
+	Response
+	Comments []PendingCommentJson `json:"comments"`
+	Page     int                  `json:"page"`
+	Total    int64                `json:"total"`
+}
+
+// Запрос на одобрение или отклонение комментария
+type ModerateCommentRequest struct {
+	ID      uint `json:"id"`
+	Approve bool `json:"approve"` // true - одобрить, false - отклонить
+}
+
+type ModerateCommentResponse struct {
+	Response
+}
+
+// Запрос на закрытие или открытие ветки комментариев
+type LockCommentThreadRequest struct {
+	ID     uint `json:"id"` // ID любого комментария ветки
+	Locked bool `json:"locked"`
+}
+
+type LockCommentThreadResponse struct {
+	Response
+}
+
+// Запрос на запрет или разрешение пользователю комментировать посты автора
+type CommentBanRequest struct {
+	UserID uint `json:"userId"`
+}
+
+type CommentBanResponse struct {
+	Response
+}
+
+// Пользователь, которому запрещено комментировать посты автора
+type BannedCommenterJson struct {
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+	Date   string `json:"date"` // Дата запрета
+}
+
+type GetCommentBansResponse struct {
+	Response
+	Users []BannedCommenterJson `json:"users"`
+}
+
+// Запрос на постановку или снятие реакции
+type ReactionRequest struct {
+	PostID uint   `json:"postId"`
+	Kind   string `json:"kind"` // Вид реакции
+}
+
+type ReactionResponse struct {
+	Response
+	Reactions   map[string]int `json:"reactions"`   // Количество реакций поста по видам
+	MyReactions []string       `json:"myReactions"` // Реакции пользователя на пост
+}
+
+type GetReactionKindsResponse struct {
+	Response
+	Kinds []string `json:"kinds"` // Доступные виды реакций
+}
+
+// Запрос на добавление поста в закладки. Если пост уже в закладках, он переносится в указанный список.
+type AddBookmarkRequest struct {
+	PostID uint `json:"postId"`
+	ListID uint `json:"listId"` // ID списка чтения, 0 - список "Сохраненное"
+}
+
+type AddBookmarkResponse struct {
+	Response
+}
+
+// Запрос на удаление поста из закладок
+type RemoveBookmarkRequest struct {
+	PostID uint `json:"postId"`
+}
+
+type RemoveBookmarkResponse struct {
+	Response
+}
+
+// Список чтения
+type ReadingListJson struct {
+	ID     uint   `json:"id"`     // ID списка, 0 - список "Сохраненное"
+	Name   string `json:"name"`   // Название списка
+	Public bool   `json:"public"` // Список виден другим пользователям
+	Count  int64  `json:"count"`  // Количество закладок в списке
+}
+
+// Запрос на создание или изменение списка чтения
+type SaveReadingListRequest struct {
+	ID     uint   `json:"id"` // ID списка, при создании не заполняется
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+type SaveReadingListResponse struct {
+	Response
+	List ReadingListJson `json:"list"`
+}
+
+// Запрос на удаление списка чтения. Закладки из списка переносятся в список "Сохраненное".
+type DeleteReadingListRequest struct {
+	ID uint `json:"id"`
+}
+
+type DeleteReadingListResponse struct {
+	Response
+}
+
+// Запрос на изменение порядка закладок в списке чтения
+type ReorderReadingListRequest struct {
+	ListID  uint   `json:"listId"`  // ID списка, 0 - список "Сохраненное"
+	PostIDs []uint `json:"postIds"` // ID постов в новом порядке, не указанные посты идут следом в прежнем порядке
+}
+
+type ReorderReadingListResponse struct {
+	Response
+}
+
+type GetReadingListsResponse struct {
+	Response
+	Lists []ReadingListJson `json:"lists"`
+}
+
+// Запрос на получение страницы списка чтения
+type GetReadingListRequest struct {
+	ListID uint `json:"listId"` // ID списка, 0 - список "Сохраненное" владельца токена
+	Page   int  `json:"page"`   // Номер страницы, начиная с 1
+	Limit  int  `json:"limit"`  // Количество закладок на странице
+}
+
+// Закладка в списке чтения
+type BookmarkJson struct {
+	PostID  uint         `json:"postId"`
+	Title   string       `json:"title"`   // Заголовок поста, у заглушки в чужом списке пустой
+	Deleted bool         `json:"deleted"` // Пост удален или недоступен, вместо него показывается заглушка
+	Post    *PostForFeed `json:"post"`    // Пост, nil - пост удален или недоступен
+	Date    string       `json:"date"`    // Дата добавления в закладки
+}
+
+type GetReadingListResponse struct {
+	Response
+	List      ReadingListJson `json:"list"`
+	Own       bool            `json:"own"` // Список принадлежит владельцу токена
+	Bookmarks []BookmarkJson  `json:"bookmarks"`
+	Page      int             `json:"page"`
+	Total     int64           `json:"total"` // Общее количество закладок
+}
+
+// Запрос на подписку на автора или отписку от него
+type FollowUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+type FollowUserResponse struct {
+	Response
+	Followers int64 `json:"followers"` // Количество подписчиков автора
+}
+
+// Запрос на получение ленты постов авторов, на которых подписан пользователь.
+// Страницы листаются курсором, а не номером: новые посты не сдвигают следующую страницу.
+type GetFollowingFeedRequest struct {
+	Cursor string `json:"cursor"` // Курсор из предыдущего ответа, пустой - первая страница
+	Limit  int    `json:"limit"`  // Количество постов на странице
+}
+
+type GetFollowingFeedResponse struct {
+	Response
+	Posts      []PostForFeed `json:"posts"`
+	NextCursor string        `json:"nextCursor"` // Курсор следующей страницы, пустой - постов больше нет
+}
+
+// Уведомление пользователя
+type NotificationJson struct {
+	ID        uint   `json:"id"`
+	Type      string `json:"type"`      // comment, follow, like, mention или report
+	Text      string `json:"text"`      // Текст уведомления
+	ActorID   uint   `json:"actorId"`   // Последний пользователь, вызвавший событие
+	ActorName string `json:"actorName"` // Имя последнего пользователя, вызвавшего событие
+	Others    int64  `json:"others"`    // Количество остальных пользователей в сгруппированном уведомлении
+	PostID    uint   `json:"postId"`
+	PostTitle string `json:"postTitle"`
+	PostURL   string `json:"postUrl"`
+	CommentID uint   `json:"commentId"`
+	ReportID  uint   `json:"reportId"`
+	Outcome   string `json:"outcome"` // Итог рассмотрения жалобы: dismissed или resolved
+	Read      bool   `json:"read"`
+	Date      string `json:"date"` // Время последнего события
+}
+
+// Запрос на получение уведомлений
+type GetNotificationsRequest struct {
+	UnreadOnly bool `json:"unreadOnly"` // Только непрочитанные
+	Page       int  `json:"page"`       // Номер страницы, начиная с 1
+	Limit      int  `json:"limit"`      // Количество уведомлений на странице
+}
+
+type GetNotificationsResponse struct {
+	Response
+	Notifications []NotificationJson `json:"notifications"`
+	Page          int                `json:"page"`
+	Total         int64              `json:"total"`  // Общее количество уведомлений
+	Unread        int64              `json:"unread"` // Количество непрочитанных уведомлений
+}
+
+// Запрос на отметку уведомления прочитанным
+type MarkNotificationReadRequest struct {
+	ID uint `json:"id"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Response
+	Unread int64 `json:"unread"` // Количество непрочитанных уведомлений
+}
+
+type GetUnreadNotificationsCountResponse struct {
+	Response
+	Unread int64 `json:"unread"` // Количество непрочитанных уведомлений
+}
+
+// Запрос на смену имени пользователя для упоминаний
+type SetUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+type SetUsernameResponse struct {
+	Response
+	Username string `json:"username"` // Сохраненное имя в нижнем регистре
+}
+
+// Запрос на блокировку или скрытие пользователя и на их отмену
+type RestrictUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+type RestrictUserResponse struct {
+	Response
+}
+
+// Заблокированный или скрытый пользователь
+type RestrictedUserJson struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Date     string `json:"date"` // Дата блокировки или скрытия
+}
+
+type GetRestrictedUsersResponse struct {
+	Response
+	Users []RestrictedUserJson `json:"users"`
+}
+
+// Запрос на жалобу на пост, комментарий или пользователя
+type ReportContentRequest struct {
+	TargetType string `json:"targetType"` // post, comment или user
+	TargetID   uint   `json:"targetId"`
+	Reason     string `json:"reason"` // Одна из причин GetReportReasons
+	Text       string `json:"text"`   // Пояснение, обязательно для причины other
+}
+
+type ReportContentResponse struct {
+	Response
+}
+
+type GetReportReasonsResponse struct {
+	Response
+	Reasons []string `json:"reasons"`
+}
+
+// Запрос на получение очереди жалоб
+type GetReportQueueRequest struct {
+	Status     string `json:"status"`     // open, dismissed, resolved или all. По умолчанию open
+	TargetType string `json:"targetType"` // Пусто - все объекты
+	Reason     string `json:"reason"`     // Пусто - все причины
+	AssigneeID uint   `json:"assigneeId"` // 0 - любой модератор
+	Unassigned bool   `json:"unassigned"` // Только жалобы без назначенного модератора
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+}
+
+// Жалоба одного пользователя
+type ReportEntryJson struct {
+	ReporterID   uint   `json:"reporterId"`
+	ReporterName string `json:"reporterName"`
+	Reason       string `json:"reason"`
+	Text         string `json:"text"`
+	Date         string `json:"date"`
+}
+
+// Жалоба в очереди модерации
+type ReportJson struct {
+	ID               uint              `json:"id"`
+	TargetType       string            `json:"targetType"`
+	TargetID         uint              `json:"targetId"`
+	TargetAuthorID   uint              `json:"targetAuthorId"`
+	TargetAuthorName string            `json:"targetAuthorName"`
+	Preview          string            `json:"preview"` // Заголовок поста, текст комментария или имя пользователя
+	PostID           uint              `json:"postId"`
+	PostURL          string            `json:"postUrl"`
+	Status           string            `json:"status"`
+	ReportsCount     int               `json:"reportsCount"`
+	Held             bool              `json:"held"`    // Объект задержан фильтром и будет опубликован, если жалобу отклонить
+	Reasons          map[string]int    `json:"reasons"` // Количество жалоб по причинам
+	Entries          []ReportEntryJson `json:"entries"`
+	AssigneeID       uint              `json:"assigneeId"`
+	AssigneeName     string            `json:"assigneeName"`
+	Action           string            `json:"action"` // Принятое решение
+	Date             string            `json:"date"`   // Время первой жалобы
+}
+
+type GetReportQueueResponse struct {
+	Response
+	Reports []ReportJson `json:"reports"`
+	Page    int          `json:"page"`
+	Total   int64        `json:"total"`
+}
+
+// Запрос на назначение жалобы модератору
+type AssignReportRequest struct {
+	ID         uint `json:"id"`
+	AssigneeID uint `json:"assigneeId"` // 0 - снять назначение
+}
+
+type AssignReportResponse struct {
+	Response
+}
+
+// Запрос на решение по жалобе
+type ResolveReportRequest struct {
+	ID          uint   `json:"id"`
+	Action      string `json:"action"`      // dismiss, hide, delete или suspend
+	SuspendDays int    `json:"suspendDays"` // Срок блокировки автора для suspend, 0 - срок по умолчанию
+}
+
+type ResolveReportResponse struct {
+	Response
+}
+
+// Запрос на назначение или снятие модератора
+type SetUserRoleRequest struct {
+	UserID uint   `json:"userId"`
+	Role   string `json:"role"` // moderator или user
+}
+
+type SetUserRoleResponse struct {
+	Response
+	Role string `json:"role"` // Новая роль пользователя
+}
+
+// Правила фильтра нового содержимого
+type FilterRulesJson struct {
+	MaxLinks           int      `json:"maxLinks"`           // Максимальное количество ссылок в тексте, 0 - без ограничения
+	MaxLinkDensity     int      `json:"maxLinkDensity"`     // Максимальное количество ссылок на 100 слов, 0 - без ограничения
+	BannedWords        []string `json:"bannedWords"`        // Запрещенные слова и фразы
+	BannedPatterns     []string `json:"bannedPatterns"`     // Запрещенные регулярные выражения, регистр не учитывается
+	DuplicateWindow    int      `json:"duplicateWindow"`    // Период поиска повторяющегося текста в часах, 0 - без проверки
+	DuplicateMinLength int      `json:"duplicateMinLength"` // Минимальная длина текста в символах для поиска повторов
+	PostsPerHour       int      `json:"postsPerHour"`       // Максимальное количество постов пользователя в час, 0 - без ограничения
+	CommentsPerHour    int      `json:"commentsPerHour"`    // Максимальное количество комментариев пользователя в час, 0 - без ограничения
+	ProbationDays      int      `json:"probationDays"`      // Испытательный срок нового аккаунта в днях
+	ProbationMaxLinks  int      `json:"probationMaxLinks"`  // Максимальное количество ссылок в тексте аккаунта на испытательном сроке
+}
+
+type GetFilterRulesResponse struct {
+	Response
+	Rules FilterRulesJson `json:"rules"`
+}
+
+// Запрос на изменение правил фильтра
+type UpdateFilterRulesRequest struct {
+	Rules FilterRulesJson `json:"rules"`
+}
+
+type UpdateFilterRulesResponse struct {
+	Response
+	Rules FilterRulesJson `json:"rules"`
+}
+
+// Запрос на получение задачи proof-of-work
+type PowChallengeRequest struct {
+	Scope string `json:"scope"` // register или reset-password
+}
+
+type PowChallengeResponse struct {
+	Response
+	Enabled    bool   `json:"enabled"`    // false - задача не требуется, остальные поля пустые
+	Challenge  string `json:"challenge"`  // Подписанная задача
+	Difficulty int    `json:"difficulty"` // Количество нулевых бит в начале хеша
+	Algorithm  string `json:"algorithm"`  // Хеш-функция: SHA-256 от строки "challenge:email:solution"
+	ExpiresAt  string `json:"expiresAt"`  // Время, до которого задачу нужно решить
+}
+
+// Уменьшенная копия изображения
+type MediaVariantJson struct {
+	Name   string `json:"name"`   // thumbnail, medium или large
+	Format string `json:"format"` // jpeg, png или webp
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int64  `json:"size"` // Размер в байтах
+}
+
+// Изображение с вариантами для адаптивной верстки
+type ImageJson struct {
+	URL      string             `json:"url"` // Исходное изображение без метаданных
+	Width    int                `json:"width"`
+	Height   int                `json:"height"`
+	Blurhash string             `json:"blurhash"` // Пусто, пока изображение обрабатывается
+	Variants []MediaVariantJson `json:"variants"` // Пусто, пока изображение обрабатывается
+}
+
+// Загруженный файл
+type MediaJson struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	URL         string             `json:"url"`
+	ContentType string             `json:"contentType"`
+	Size        int64              `json:"size"`     // Размер в байтах
+	Markdown    string             `json:"markdown"` // Разметка для вставки файла в текст поста
+	UsedIn      int64              `json:"usedIn"`   // Количество постов, ссылающихся на файл
+	Status      string             `json:"status"`   // processing - файл обрабатывается и пока недоступен, ready или failed
+	Width       int                `json:"width"`
+	Height      int                `json:"height"`
+	Blurhash    string             `json:"blurhash"`
+	Variants    []MediaVariantJson `json:"variants"`
+	CreatedAt   string             `json:"createdAt"`
+}
+
+type UploadMediaResponse struct {
+	Response
+	Media MediaJson `json:"media"`
+}
+
+type GetMediaLibraryRequest struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+type GetMediaLibraryResponse struct {
+	Response
+	Media      []MediaJson `json:"media"`
+	Page       int         `json:"page"`
+	Total      int64       `json:"total"`
+	QuotaUsed  int64       `json:"quotaUsed"`  // Размер загруженных файлов в байтах
+	QuotaLimit int64       `json:"quotaLimit"` // Сколько байт можно загрузить, 0 - без ограничения
+}
+
+type DeleteMediaRequest struct {
+	ID uint `json:"id"`
+}
+
+type DeleteMediaResponse struct {
+	Response
+}
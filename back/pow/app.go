@@ -0,0 +1,188 @@
+// В данном пакете реализуются задачи proof-of-work, которые клиент решает перед запросами, отправляющими письма.
+// Задача подписана HMAC, поэтому сервер проверяет ее без хранения выданных задач.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Algorithm хеш-функция задачи
+const Algorithm = "sha256"
+
+// Challenger выдает и проверяет задачи. Сложность растет с количеством задач, выданных за последнее окно.
+type Challenger struct {
+	secret         []byte
+	ttl            time.Duration
+	baseDifficulty int
+	maxDifficulty  int
+	rateWindow     time.Duration
+	rateStep       int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	issued      int // Задач выдано в текущем окне
+	previous    int // Задач выдано в предыдущем окне
+
+	// Использованные задачи хранятся до истечения их срока, чтобы одно решение нельзя было использовать повторно
+	spent *cache.Cache
+}
+
+// NewChallenger создает выдачу задач с ключом подписи secret
+func NewChallenger(secret []byte, ttl time.Duration, baseDifficulty, maxDifficulty int, rateWindow time.Duration, rateStep int) *Challenger {
+	return &Challenger{
+		secret:         secret,
+		ttl:            ttl,
+		baseDifficulty: baseDifficulty,
+		maxDifficulty:  maxDifficulty,
+		rateWindow:     rateWindow,
+		rateStep:       rateStep,
+		windowStart:    time.Now(),
+		spent:          cache.New(ttl, ttl),
+	}
+}
+
+// difficulty учитывает новую задачу и возвращает ее сложность. Нагрузка считается по большему
+// из текущего и предыдущего окна, чтобы сложность не падала сразу на границе окна.
+func (c *Challenger) difficulty(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elapsed := now.Sub(c.windowStart); elapsed >= c.rateWindow {
+		c.previous = c.issued
+		if elapsed >= 2*c.rateWindow {
+			c.previous = 0
+		}
+		c.issued = 0
+		c.windowStart = now
+	}
+	c.issued++
+
+	load := max(c.issued, c.previous)
+	difficulty := c.baseDifficulty
+	if c.rateStep > 0 {
+		difficulty += load / c.rateStep
+	}
+	return min(difficulty, c.maxDifficulty)
+}
+
+// sign возвращает подпись содержимого задачи
+func (c *Challenger) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Issue выдает задачу для назначения scope. Возвращает задачу, ее сложность и срок действия.
+func (c *Challenger) Issue(scope string) (string, int, time.Time, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", 0, time.Time{}, err
+	}
+
+	now := time.Now()
+	difficulty := c.difficulty(now)
+	expires := now.Add(c.ttl)
+	payload := fmt.Sprintf("%s.%d.%d.%s", scope, difficulty, expires.Unix(), hex.EncodeToString(nonce))
+	return payload + "." + c.sign(payload), difficulty, expires, nil
+}
+
+// Verify проверяет решение задачи challenge для назначения scope и адреса subject. Задача при этом не расходуется:
+// ее нужно отметить использованной через Spend, когда действие действительно выполняется, чтобы ошибка
+// в остальных данных запроса не заставляла решать задачу заново.
+func (c *Challenger) Verify(challenge, scope, subject, solution string) error {
+	if challenge == "" || solution == "" {
+		return fmt.Errorf("Решите задачу проверки, чтобы продолжить")
+	}
+
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 5 {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(c.sign(payload))) {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+	if parts[0] != scope {
+		return fmt.Errorf("Задача проверки выдана для другого действия")
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+	if time.Now().Unix() > expires {
+		return fmt.Errorf("Срок задачи проверки истек, запросите новую")
+	}
+
+	if LeadingZeroBits(Hash(challenge, subject, solution)) < difficulty {
+		return fmt.Errorf("Неверное решение задачи проверки")
+	}
+	if _, spent := c.spent.Get(parts[3]); spent {
+		return fmt.Errorf("Задача проверки уже использована, запросите новую")
+	}
+	return nil
+}
+
+// Spend отмечает проверенную через Verify задачу использованной. Решенные задачи хранятся до истечения их срока,
+// поэтому одно решение нельзя использовать повторно.
+func (c *Challenger) Spend(challenge string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 5 {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Недействительная задача проверки")
+	}
+
+	// Add не перезаписывает ключ, поэтому из двух одновременных запросов с одним решением пройдет один
+	if err := c.spent.Add(parts[3], true, time.Until(time.Unix(expires, 0))); err != nil {
+		return fmt.Errorf("Задача проверки уже использована, запросите новую")
+	}
+	return nil
+}
+
+// Hash возвращает хеш решения solution задачи challenge для адреса subject
+func Hash(challenge, subject, solution string) []byte {
+	sum := sha256.Sum256([]byte(challenge + ":" + subject + ":" + solution))
+	return sum[:]
+}
+
+// LeadingZeroBits возвращает количество нулевых бит в начале хеша
+func LeadingZeroBits(hash []byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+// Solve перебирает решения задачи challenge для адреса subject, пока не найдет подходящее.
+// Так же задачу решает клиент.
+func Solve(challenge, subject string, difficulty int) string {
+	for n := 0; ; n++ {
+		solution := strconv.Itoa(n)
+		if LeadingZeroBits(Hash(challenge, subject, solution)) >= difficulty {
+			return solution
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package pow
+
+import (
+	"app/config"
+	"time"
+)
+
+// App выдача задач. nil, если задачи не требуются.
+var App *Challenger
+
+func Init() error {
+	conf := config.File.PowConfig
+	if !conf.Enabled {
+		App = nil
+		return nil
+	}
+
+	secret := conf.Secret
+	if secret == "" {
+		secret = config.File.JWTTokenPassword
+	}
+
+	App = NewChallenger([]byte(secret), time.Duration(conf.ChallengeTTL)*time.Minute,
+		conf.BaseDifficulty, conf.MaxDifficulty, time.Duration(conf.RateWindow)*time.Minute, conf.RateStep)
+	return nil
+}
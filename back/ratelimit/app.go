@@ -0,0 +1,173 @@
+// В данном пакете реализуется ограничение частоты запросов по алгоритму корзины токенов.
+// Запросы делятся на группы маршрутов, у каждой группы свое ограничение и свой способ различать клиентов.
+package ratelimit
+
+import (
+	"app/model"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Group ограничение группы маршрутов
+type Group struct {
+	Limit Limit
+	Key   string // По чему считать запросы: model.RateLimitByIP, model.RateLimitByUser или model.RateLimitByBoth
+}
+
+// Limiter ограничитель частоты запросов
+type Limiter struct {
+	store          Store
+	groups         map[string]Group
+	allowList      []*net.IPNet
+	trustedProxies []*net.IPNet
+}
+
+// NewLimiter создает ограничитель с хранилищем store. Группы без ограничения или с нулевой частотой не ограничиваются,
+// запросы с адресов из allowList не ограничиваются никогда. Заголовок X-Forwarded-For учитывается только
+// в запросах от прокси из trustedProxies.
+func NewLimiter(store Store, groups map[string]Group, allowList, trustedProxies []string) (*Limiter, error) {
+	limiter := &Limiter{
+		store:  store,
+		groups: make(map[string]Group),
+	}
+
+	for name, group := range groups {
+		switch group.Key {
+		case model.RateLimitByIP, model.RateLimitByUser, model.RateLimitByBoth:
+		default:
+			return nil, fmt.Errorf("Неизвестный способ подсчета запросов группы %s: %s", name, group.Key)
+		}
+		if group.Limit.Rate <= 0 {
+			continue
+		}
+		group.Limit.Burst = max(group.Limit.Burst, 1)
+		limiter.groups[name] = group
+	}
+
+	var err error
+	if limiter.allowList, err = parseNetworks(allowList); err != nil {
+		return nil, fmt.Errorf("Недопустимый адрес в списке без ограничений: %v", err)
+	}
+	if limiter.trustedProxies, err = parseNetworks(trustedProxies); err != nil {
+		return nil, fmt.Errorf("Недопустимый адрес в списке доверенных прокси: %v", err)
+	}
+
+	return limiter, nil
+}
+
+// parseNetworks разбирает список адресов и подсетей. Отдельный адрес записывается как подсеть из одного адреса.
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// contains возвращает true, если адрес ip входит в одну из подсетей networks
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP возвращает адрес клиента. X-Forwarded-For учитывается, только если запрос пришел от доверенного прокси.
+// Прокси дописывают адреса в конец заголовка, а начало заполняет клиент, поэтому адресом клиента считается
+// последний адрес, не принадлежащий доверенным прокси.
+func (l *Limiter) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil || !contains(l.trustedProxies, remote) {
+		return host
+	}
+
+	client := remote
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			// Дальше идут адреса, которые не проверить: считаем клиентом последний разобранный адрес
+			break
+		}
+		client = ip
+		if !contains(l.trustedProxies, ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// Allowed возвращает true, если адрес ip в списке без ограничений
+func (l *Limiter) Allowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && contains(l.allowList, parsed)
+}
+
+// Take учитывает запрос группы group с адреса ip от пользователя userID (0 - неавторизованный запрос).
+// Возвращает false, если группа не ограничена. При подсчете и по адресу, и по пользователю
+// возвращается более строгий из двух результатов, а после отказа по адресу токен пользователя не расходуется.
+func (l *Limiter) Take(group, ip string, userID uint) (Result, bool, error) {
+	g, ok := l.groups[group]
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	var keys []string
+	if g.Key != model.RateLimitByUser || userID == 0 {
+		keys = append(keys, fmt.Sprintf("%s:ip:%s", group, ip))
+	}
+	if g.Key != model.RateLimitByIP && userID != 0 {
+		keys = append(keys, fmt.Sprintf("%s:user:%d", group, userID))
+	}
+
+	now := time.Now()
+	var result Result
+	for i, key := range keys {
+		r, err := l.store.Take(key, g.Limit, now)
+		if err != nil {
+			return Result{}, false, err
+		}
+		if i == 0 || stricter(r, result) {
+			result = r
+		}
+		if !r.Allowed {
+			break
+		}
+	}
+	return result, true, nil
+}
+
+// stricter возвращает true, если результат a строже результата b
+func stricter(a, b Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
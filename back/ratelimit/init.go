@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"app/config"
+	"app/model"
+	"time"
+)
+
+// App ограничитель частоты запросов. nil, если ограничение отключено.
+var App *Limiter
+
+func Init() error {
+	conf := config.File.RateLimitConfig
+	if !conf.Enabled {
+		App = nil
+		return nil
+	}
+
+	groups := map[string]Group{
+		model.RateLimitAuth:  {Limit: perMinute(conf.AuthRate, conf.AuthBurst), Key: conf.AuthKey},
+		model.RateLimitWrite: {Limit: perMinute(conf.WriteRate, conf.WriteBurst), Key: conf.WriteKey},
+		model.RateLimitRead:  {Limit: perMinute(conf.ReadRate, conf.ReadBurst), Key: conf.ReadKey},
+	}
+
+	limiter, err := NewLimiter(NewMemoryStore(time.Minute), groups, conf.AllowList, conf.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	App = limiter
+	return nil
+}
+
+// perMinute возвращает ограничение rate запросов в минуту с вместимостью burst
+func perMinute(rate, burst int) Limit {
+	return Limit{Rate: float64(rate) / 60, Burst: burst}
+}
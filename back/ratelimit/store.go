@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Limit ограничение корзины токенов
+type Limit struct {
+	Rate  float64 // Пополнение корзины в токенах в секунду
+	Burst int     // Вместимость корзины
+}
+
+// Result результат списания токена
+type Result struct {
+	Allowed    bool          // Запрос разрешен
+	Limit      int           // Вместимость корзины
+	Remaining  int           // Сколько токенов осталось
+	RetryAfter time.Duration // Через сколько появится токен, если запрос не разрешен
+	Reset      time.Duration // Через сколько корзина заполнится полностью
+}
+
+// Store хранилище корзин. Take должен списывать токен атомарно, чтобы корзины можно было
+// перенести в общее хранилище и делить между несколькими экземплярами приложения.
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket корзина токенов
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore хранилище корзин в памяти процесса. Заполнившиеся корзины удаляются.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets *cache.Cache
+}
+
+// NewMemoryStore создает хранилище в памяти, которое удаляет заполнившиеся корзины раз в cleanupInterval
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	return &MemoryStore{
+		buckets: cache.New(cache.NoExpiration, cleanupInterval),
+	}
+}
+
+// Take списывает токен из корзины key
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &bucket{tokens: float64(limit.Burst), updated: now}
+	if value, found := s.buckets.Get(key); found {
+		b = value.(*bucket)
+	}
+	result := take(b, limit, now)
+
+	// Полная корзина ничем не отличается от новой, поэтому хранится только до заполнения
+	s.buckets.Set(key, b, result.Reset+time.Second)
+	return result, nil
+}
+
+// take пополняет корзину b за прошедшее время и списывает из нее токен
+func take(b *bucket, limit Limit, now time.Time) Result {
+	burst := float64(limit.Burst)
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*limit.Rate)
+	}
+	b.updated = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / limit.Rate)
+	return result
+}
+
+// seconds переводит секунды в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
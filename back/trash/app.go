@@ -0,0 +1,58 @@
+// В данном пакете реализуется периодическая очистка корзины удаленных постов.
+package trash
+
+import (
+	"app/auth"
+	"app/log"
+	"context"
+	"time"
+)
+
+// Purger периодически удаляет посты, срок хранения которых в корзине истек
+type Purger struct {
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewPurger создает очистку корзины с указанным интервалом запуска
+func NewPurger(interval time.Duration) *Purger {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Purger{
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start запускает очистку корзины. Первая очистка выполняется сразу.
+func (p *Purger) Start() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop останавливает очистку корзины
+func (p *Purger) Stop() {
+	p.cancel()
+}
+
+func (p *Purger) purge() {
+	count, err := auth.PurgeDeletedPosts()
+	if err != nil {
+		log.App.Error("Ошибка при очистке корзины: ", err)
+		return
+	}
+	if count > 0 {
+		log.App.Info("Корзина очищена, окончательно удалено постов: ", count)
+	}
+}
@@ -0,0 +1,24 @@
+package trash
+
+import (
+	"app/config"
+	"fmt"
+	"time"
+)
+
+var App *Purger
+
+func Init() error {
+	conf := config.File.TrashConfig
+	if conf.PurgeInterval <= 0 {
+		return fmt.Errorf("Недопустимый интервал очистки корзины: %d. Укажите TRASH_PURGE_INTERVAL больше 0", conf.PurgeInterval)
+	}
+	// При нулевом сроке хранения корзина очищалась бы сразу после удаления поста
+	if conf.RetentionDays <= 0 {
+		return fmt.Errorf("Недопустимый срок хранения удаленных постов: %d. Укажите TRASH_RETENTION_DAYS больше 0", conf.RetentionDays)
+	}
+
+	App = NewPurger(time.Duration(conf.PurgeInterval) * time.Minute)
+	go App.Start()
+	return nil
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"app/model"
+	"strings"
+)
+
+// DiffLines выполняет построчное сравнение двух текстов алгоритмом Майерса.
+// Возвращает последовательность строк с операциями equal, insert и delete.
+func DiffLines(a, b string) []model.DiffLine {
+	return DiffSlices(splitLines(a), splitLines(b))
+}
+
+// DiffSlices выполняет сравнение двух последовательностей строк алгоритмом Майерса
+func DiffSlices(x, y []string) []model.DiffLine {
+	// Общие начало и конец не участвуют в поиске, это сильно сокращает работу на типичных правках
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]model.DiffLine, 0, len(x)+len(y))
+	for _, line := range x[:prefix] {
+		result = append(result, model.DiffLine{Op: model.DiffEqual, Text: line})
+	}
+	result = append(result, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		result = append(result, model.DiffLine{Op: model.DiffEqual, Text: line})
+	}
+
+	return result
+}
+
+// maxDiffEdits ограничивает количество правок, которое ищет myers. Память на восстановление пути растет
+// как квадрат количества правок, поэтому совсем разные большие тексты сравниваются как полная замена.
+const maxDiffEdits = 1000
+
+// myers находит кратчайший сценарий правок между x и y. Если правок больше maxDiffEdits,
+// возвращает удаление всех строк x и вставку всех строк y.
+func myers(x, y []string) []model.DiffLine {
+	n, m := len(x), len(y)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] хранит диагонали -d..d после шага d, чтобы восстановить путь
+	var trace [][]int
+	found := false
+	for d := 0; d <= max && d <= maxDiffEdits; d++ {
+		for k := -d; k <= d; k += 2 {
+			var xi int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				xi = v[offset+k+1]
+			} else {
+				xi = v[offset+k-1] + 1
+			}
+			yi := xi - k
+			for xi < n && yi < m && x[xi] == y[yi] {
+				xi++
+				yi++
+			}
+			v[offset+k] = xi
+		}
+
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		if v[offset+n-m] >= n && n-m >= -d && n-m <= d {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return replaceAll(x, y)
+	}
+
+	// Восстанавливаем путь с конца
+	var reversed []model.DiffLine
+	xi, yi := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		get := func(k int) int { return prev[k+d-1] }
+
+		k := xi - yi
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+
+		for xi > prevX && yi > prevY {
+			xi--
+			yi--
+			reversed = append(reversed, model.DiffLine{Op: model.DiffEqual, Text: x[xi]})
+		}
+		if xi == prevX {
+			yi--
+			reversed = append(reversed, model.DiffLine{Op: model.DiffInsert, Text: y[yi]})
+		} else {
+			xi--
+			reversed = append(reversed, model.DiffLine{Op: model.DiffDelete, Text: x[xi]})
+		}
+	}
+	for xi > 0 && yi > 0 {
+		xi--
+		yi--
+		reversed = append(reversed, model.DiffLine{Op: model.DiffEqual, Text: x[xi]})
+	}
+
+	result := make([]model.DiffLine, len(reversed))
+	for i, line := range reversed {
+		result[len(reversed)-1-i] = line
+	}
+	return result
+}
+
+// replaceAll возвращает сценарий, в котором все строки x удаляются, а все строки y вставляются
+func replaceAll(x, y []string) []model.DiffLine {
+	result := make([]model.DiffLine, 0, len(x)+len(y))
+	for _, line := range x {
+		result = append(result, model.DiffLine{Op: model.DiffDelete, Text: line})
+	}
+	for _, line := range y {
+		result = append(result, model.DiffLine{Op: model.DiffInsert, Text: line})
+	}
+	return result
+}
+
+// splitLines разбивает текст на строки, пустой текст не содержит строк
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
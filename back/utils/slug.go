@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// slugMaxLength максимальная длина slug без числового суффикса
+const slugMaxLength = 80
+
+// cyrillicToLatin таблица транслитерации кириллицы в латиницу
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Transliterate переводит кириллицу в латиницу, остальные символы оставляет как есть
+func Transliterate(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		lower := unicode.ToLower(r)
+		latin, ok := cyrillicToLatin[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if lower != r && latin != "" {
+			// Заглавная буква остается заглавной: Ж -> Zh
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+// Slugify формирует из текста человекочитаемый идентификатор для URL: латиница в нижнем регистре,
+// цифры и дефисы. Для текста без подходящих символов возвращает fallback.
+func Slugify(text, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(Transliterate(text)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > slugMaxLength {
+		slug = slug[:slugMaxLength]
+		// Не обрываем слово посередине, если есть где обрезать
+		if i := strings.LastIndexByte(slug, '-'); i > slugMaxLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
@@ -4,6 +4,8 @@ import (
 	"app/config"
 	"app/log"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
@@ -21,9 +23,7 @@ func NewWebSocketServer() *WebApp {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		CheckOrigin:     checkOrigin,
 	}
 
 	app := &WebApp{
@@ -35,6 +35,33 @@ func NewWebSocketServer() *WebApp {
 	return app
 }
 
+// checkOrigin разрешает подключение со страниц самого приложения, с сайтов из LIVE_ALLOWED_ORIGINS
+// и без заголовка Origin, который браузеры передают всегда
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(originURL.Host, r.Host) {
+		return true
+	}
+
+	appURL, err := url.Parse(config.File.WebConfig.APPURL)
+	if err == nil && strings.EqualFold(originURL.Scheme, appURL.Scheme) && strings.EqualFold(originURL.Host, appURL.Host) {
+		return true
+	}
+	for _, allowed := range config.File.LiveConfig.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // StartServer запускает сервер. Данные для запуска берутся из конфига.
 func (app *WebApp) StartServer() error {
 	conf := config.File.WebConfig
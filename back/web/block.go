@@ -0,0 +1,227 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleBlockUser обрабатывает запрос на блокировку пользователя
+// @Summary Блокировка пользователя
+// @Description Заблокированный пользователь не может ставить реакции, комментировать, упоминать и подписываться на владельца токена, а посты друг друга не видят оба. Подписки пользователей друг на друга удаляются.
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param request body model.RestrictUserRequest true "Запрос на блокировку пользователя"
+// @Success 200 {object} model.RestrictUserResponse "Пользователь заблокирован"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/block-user [post]
+func (app *WebApp) HandleBlockUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestrictUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.BlockUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при блокировке пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUnblockUser обрабатывает запрос на снятие блокировки пользователя
+// @Summary Снятие блокировки пользователя
+// @Description Снимает блокировку пользователя. Удаленные при блокировке подписки не восстанавливаются.
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param request body model.RestrictUserRequest true "Запрос на снятие блокировки пользователя"
+// @Success 200 {object} model.RestrictUserResponse "Пользователь разблокирован"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/unblock-user [post]
+func (app *WebApp) HandleUnblockUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestrictUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UnblockUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при разблокировке пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleMuteUser обрабатывает запрос на скрытие пользователя
+// @Summary Скрытие пользователя
+// @Description Скрывает посты пользователя из лент владельца токена. Скрытый пользователь об этом не узнает.
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param request body model.RestrictUserRequest true "Запрос на скрытие пользователя"
+// @Success 200 {object} model.RestrictUserResponse "Пользователь скрыт"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/mute-user [post]
+func (app *WebApp) HandleMuteUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestrictUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.MuteUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при скрытии пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUnmuteUser обрабатывает запрос на отмену скрытия пользователя
+// @Summary Отмена скрытия пользователя
+// @Description Возвращает посты пользователя в ленты владельца токена.
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param request body model.RestrictUserRequest true "Запрос на отмену скрытия пользователя"
+// @Success 200 {object} model.RestrictUserResponse "Пользователь больше не скрыт"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/unmute-user [post]
+func (app *WebApp) HandleUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestrictUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UnmuteUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отмене скрытия пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetBlockedUsers обрабатывает запрос на получение заблокированных пользователей
+// @Summary Заблокированные пользователи
+// @Description Возвращает пользователей, заблокированных владельцем токена, начиная с последних.
+// @Tags block
+// @Produce json
+// @Success 200 {object} model.GetRestrictedUsersResponse "Заблокированные пользователи получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-blocked-users [post]
+func (app *WebApp) HandleGetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetBlockedUsers(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении заблокированных пользователей: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetMutedUsers обрабатывает запрос на получение скрытых пользователей
+// @Summary Скрытые пользователи
+// @Description Возвращает пользователей, скрытых владельцем токена, начиная с последних.
+// @Tags block
+// @Produce json
+// @Success 200 {object} model.GetRestrictedUsersResponse "Скрытые пользователи получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-muted-users [post]
+func (app *WebApp) HandleGetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetMutedUsers(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении скрытых пользователей: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,317 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleAddBookmark обрабатывает добавление поста в закладки
+// @Summary Добавление закладки
+// @Description Добавляет пост в закладки или переносит его в другой список чтения.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.AddBookmarkRequest true "Запрос на добавление закладки"
+// @Success 200 {object} model.AddBookmarkResponse "Пост добавлен в закладки"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/add-bookmark [post]
+func (app *WebApp) HandleAddBookmark(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.AddBookmarkRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.AddBookmark(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при добавлении закладки: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRemoveBookmark обрабатывает удаление поста из закладок
+// @Summary Удаление закладки
+// @Description Удаляет пост из закладок пользователя.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.RemoveBookmarkRequest true "Запрос на удаление закладки"
+// @Success 200 {object} model.RemoveBookmarkResponse "Пост удален из закладок"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/remove-bookmark [post]
+func (app *WebApp) HandleRemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RemoveBookmarkRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.RemoveBookmark(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при удалении закладки: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleCreateReadingList обрабатывает создание списка чтения
+// @Summary Создание списка чтения
+// @Description Создает именованный список чтения, закрытый или публичный.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.SaveReadingListRequest true "Запрос на создание списка чтения"
+// @Success 200 {object} model.SaveReadingListResponse "Список чтения создан"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/create-reading-list [post]
+func (app *WebApp) HandleCreateReadingList(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.SaveReadingListRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.CreateReadingList(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при создании списка чтения: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateReadingList обрабатывает изменение списка чтения
+// @Summary Изменение списка чтения
+// @Description Переименовывает список чтения и меняет его видимость.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.SaveReadingListRequest true "Запрос на изменение списка чтения"
+// @Success 200 {object} model.SaveReadingListResponse "Список чтения изменен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/update-reading-list [post]
+func (app *WebApp) HandleUpdateReadingList(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.SaveReadingListRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UpdateReadingList(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении списка чтения: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteReadingList обрабатывает удаление списка чтения
+// @Summary Удаление списка чтения
+// @Description Удаляет список чтения, закладки из него переносятся в список "Сохраненное".
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteReadingListRequest true "Запрос на удаление списка чтения"
+// @Success 200 {object} model.DeleteReadingListResponse "Список чтения удален"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/delete-reading-list [post]
+func (app *WebApp) HandleDeleteReadingList(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.DeleteReadingListRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.DeleteReadingList(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при удалении списка чтения: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleReorderReadingList обрабатывает изменение порядка закладок в списке чтения
+// @Summary Порядок закладок
+// @Description Меняет порядок закладок в списке чтения.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.ReorderReadingListRequest true "Запрос на изменение порядка закладок"
+// @Success 200 {object} model.ReorderReadingListResponse "Порядок закладок изменен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/reorder-reading-list [post]
+func (app *WebApp) HandleReorderReadingList(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ReorderReadingListRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.ReorderReadingList(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении порядка закладок: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetReadingLists обрабатывает запрос на получение списков чтения пользователя
+// @Summary Списки чтения
+// @Description Возвращает списки чтения пользователя с количеством закладок.
+// @Tags bookmarks
+// @Produce json
+// @Success 200 {object} model.GetReadingListsResponse "Списки чтения получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-reading-lists [post]
+func (app *WebApp) HandleGetReadingLists(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetReadingLists(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении списков чтения: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetReadingList обрабатывает запрос на получение страницы списка чтения
+// @Summary Список чтения
+// @Description Возвращает страницу закладок списка чтения. Удаленные посты показываются заглушками.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.GetReadingListRequest true "Запрос на получение списка чтения"
+// @Success 200 {object} model.GetReadingListResponse "Список чтения получен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-reading-list [post]
+func (app *WebApp) HandleGetReadingList(w http.ResponseWriter, r *http.Request) {
+	// Токен необязателен: публичный список чтения доступен без авторизации
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
+	var req model.GetReadingListRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetReadingList(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении списка чтения: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
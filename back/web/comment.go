@@ -0,0 +1,169 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleNewComment обрабатывает создание комментария
+// @Summary Создание комментария
+// @Description Добавляет комментарий к посту или ответ на комментарий. Автор поста получает уведомление.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.NewCommentRequest true "Запрос на создание комментария"
+// @Success 200 {object} model.NewCommentResponse "Комментарий добавлен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/new-comment [post]
+func (app *WebApp) HandleNewComment(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.NewCommentRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.NewComment(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при создании комментария: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateComment обрабатывает изменение комментария
+// @Summary Изменение комментария
+// @Description Изменяет текст комментария. Автор может изменить комментарий в течение COMMENT_EDIT_WINDOW минут после создания.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateCommentRequest true "Запрос на изменение комментария"
+// @Success 200 {object} model.UpdateCommentResponse "Комментарий изменен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/update-comment [post]
+func (app *WebApp) HandleUpdateComment(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.UpdateCommentRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UpdateComment(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении комментария: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteComment обрабатывает удаление комментария
+// @Summary Удаление комментария
+// @Description Удаляет комментарий. Доступно автору комментария, автору поста и администратору.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteCommentRequest true "Запрос на удаление комментария"
+// @Success 200 {object} model.DeleteCommentResponse "Комментарий удален"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/delete-comment [post]
+func (app *WebApp) HandleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.DeleteCommentRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.DeleteComment(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при удалении комментария: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetComments обрабатывает запрос на получение комментариев поста
+// @Summary Получение комментариев
+// @Description Возвращает страницу веток комментариев поста в виде дерева. Авторизация необязательна.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.GetCommentsRequest true "Запрос на получение комментариев"
+// @Success 200 {object} model.GetCommentsResponse "Комментарии получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-comments [post]
+func (app *WebApp) HandleGetComments(w http.ResponseWriter, r *http.Request) {
+	// Комментарии доступны и без авторизации
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
+	var req model.GetCommentsRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetComments(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,279 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleSetCommentMode обрабатывает изменение режима комментариев поста
+// @Summary Режим комментариев
+// @Description Открывает или закрывает комментарии к посту либо включает одобрение комментариев. Доступно автору поста и администратору.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.SetCommentModeRequest true "Запрос на изменение режима комментариев"
+// @Success 200 {object} model.SetCommentModeResponse "Режим комментариев изменен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/set-comment-mode [post]
+func (app *WebApp) HandleSetCommentMode(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.SetCommentModeRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.SetCommentMode(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении режима комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetCommentQueue обрабатывает запрос на получение очереди модерации комментариев
+// @Summary Очередь модерации комментариев
+// @Description Возвращает комментарии, ожидающие одобрения. Автор получает комментарии к своим постам, администратор и модератор - ко всем постам.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.GetCommentQueueRequest true "Запрос на получение очереди модерации"
+// @Success 200 {object} model.GetCommentQueueResponse "Очередь комментариев получена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-comment-queue [post]
+func (app *WebApp) HandleGetCommentQueue(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetCommentQueueRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetCommentQueue(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении очереди комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleModerateComment обрабатывает одобрение или отклонение комментария
+// @Summary Модерация комментария
+// @Description Одобряет или отклоняет комментарий, ожидающий одобрения. Доступно автору поста, администратору и модератору.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.ModerateCommentRequest true "Запрос на модерацию комментария"
+// @Success 200 {object} model.ModerateCommentResponse "Комментарий одобрен или отклонен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/moderate-comment [post]
+func (app *WebApp) HandleModerateComment(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ModerateCommentRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.ModerateComment(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при модерации комментария: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleLockCommentThread обрабатывает закрытие и открытие ветки комментариев
+// @Summary Закрытие ветки комментариев
+// @Description Закрывает или открывает для ответов ветку, в которую входит комментарий. Доступно автору поста, администратору и модератору.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.LockCommentThreadRequest true "Запрос на закрытие ветки"
+// @Success 200 {object} model.LockCommentThreadResponse "Ветка закрыта или открыта"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/lock-comment-thread [post]
+func (app *WebApp) HandleLockCommentThread(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.LockCommentThreadRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.LockCommentThread(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при закрытии ветки комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleBanCommenter обрабатывает запрет пользователю комментировать посты автора
+// @Summary Запрет комментариев
+// @Description Запрещает пользователю комментировать посты текущего пользователя.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.CommentBanRequest true "Запрос на запрет комментариев"
+// @Success 200 {object} model.CommentBanResponse "Пользователю запрещено комментировать"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/ban-commenter [post]
+func (app *WebApp) HandleBanCommenter(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.CommentBanRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.BanCommenter(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при запрете комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUnbanCommenter обрабатывает снятие запрета комментировать посты автора
+// @Summary Снятие запрета комментариев
+// @Description Разрешает пользователю снова комментировать посты текущего пользователя.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body model.CommentBanRequest true "Запрос на снятие запрета"
+// @Success 200 {object} model.CommentBanResponse "Запрет комментариев снят"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/unban-commenter [post]
+func (app *WebApp) HandleUnbanCommenter(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.CommentBanRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UnbanCommenter(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при снятии запрета комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetCommentBans обрабатывает запрос на получение списка запретов комментариев
+// @Summary Список запретов комментариев
+// @Description Возвращает пользователей, которым запрещено комментировать посты текущего пользователя.
+// @Tags comments
+// @Produce json
+// @Success 200 {object} model.GetCommentBansResponse "Список получен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-comment-bans [post]
+func (app *WebApp) HandleGetCommentBans(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetCommentBans(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении запретов комментариев: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleGetFilterRules обрабатывает запрос на получение правил фильтра содержимого
+// @Summary Правила фильтра
+// @Description Возвращает действующие правила фильтра новых постов и комментариев. Доступно только администратору.
+// @Tags filter
+// @Produce json
+// @Success 200 {object} model.GetFilterRulesResponse "Правила фильтра получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-filter-rules [post]
+func (app *WebApp) HandleGetFilterRules(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetFilterRules(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении правил фильтра: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateFilterRules обрабатывает запрос на изменение правил фильтра содержимого
+// @Summary Изменение правил фильтра
+// @Description Сохраняет правила фильтра и сразу применяет их к новым постам и комментариям. Посты и комментарии, не прошедшие фильтр, попадают в очередь жалоб и публикуются, если жалобу отклонить. Доступно только администратору.
+// @Tags filter
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateFilterRulesRequest true "Запрос на изменение правил фильтра"
+// @Success 200 {object} model.UpdateFilterRulesResponse "Правила фильтра сохранены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/update-filter-rules [post]
+func (app *WebApp) HandleUpdateFilterRules(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.UpdateFilterRulesRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UpdateFilterRules(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении правил фильтра: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
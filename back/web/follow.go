@@ -0,0 +1,131 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleFollowUser обрабатывает подписку на автора
+// @Summary Подписка на автора
+// @Description Подписывает пользователя на автора. Автор получает уведомление о новом подписчике.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param request body model.FollowUserRequest true "Запрос на подписку на автора"
+// @Success 200 {object} model.FollowUserResponse "Вы подписались на автора"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/follow-user [post]
+func (app *WebApp) HandleFollowUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.FollowUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.FollowUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при подписке на автора: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUnfollowUser обрабатывает отписку от автора
+// @Summary Отписка от автора
+// @Description Отписывает пользователя от автора.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param request body model.FollowUserRequest true "Запрос на отписку от автора"
+// @Success 200 {object} model.FollowUserResponse "Вы отписались от автора"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/unfollow-user [post]
+func (app *WebApp) HandleUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.FollowUserRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UnfollowUser(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отписке от автора: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetFollowingFeed обрабатывает запрос на получение ленты постов авторов из подписок
+// @Summary Лента подписок на авторов
+// @Description Возвращает посты авторов, на которых подписан пользователь, начиная с новых. Страницы листаются курсором.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param request body model.GetFollowingFeedRequest true "Запрос на получение ленты подписок"
+// @Success 200 {object} model.GetFollowingFeedResponse "Лента подписок получена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-following-feed [post]
+func (app *WebApp) HandleGetFollowingFeed(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetFollowingFeedRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetFollowingFeed(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении ленты подписок на авторов: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -2,11 +2,11 @@ package web
 
 import (
 	"app/auth"
-	"app/db"
 	"app/log"
 	"app/model"
 	"app/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -16,6 +16,7 @@ const secureCookie = false
 // HandleRegistrationStarted обрабатывает начало регистрации
 // @Summary Начало регистрации
 // @Description Обрабатывает запрос на начало регистрации пользователя, проверяет корректность данных и отправляет код подтверждения на почту.
+// @Description Если задачи proof-of-work включены, запрос должен содержать решение задачи /api/pow-challenge с назначением register.
 // @Tags registration
 // @Accept json
 // @Produce json
@@ -34,7 +35,7 @@ func (app *WebApp) HandleRegistrationStarted(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	log.App.Info("Received registration request for account: ", req)
-	response, token, err := auth.RegisterUser(req.Email, req.Password, req.Name)
+	response, token, err := auth.RegisterUser(req.Email, req.Password, req.Name, req.ProofOfWork)
 	if err != nil {
 		log.App.Error(r.RemoteAddr, " failed to register student: ", err)
 		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
@@ -234,15 +235,16 @@ func (app *WebApp) HandleLogout(w http.ResponseWriter, r *http.Request) {
 // HandleResetPasswordStarted обрабатывает начало сброса пароля
 // @Summary Начало сброса пароля
 // @Description Обрабатывает запрос на начало сброса пароля, отправляет код подтверждения на почту.
+// @Description Если задачи proof-of-work включены, запрос должен содержать решение задачи /api/pow-challenge с назначением reset-password.
 // @Tags password
 // @Accept json
 // @Produce json
-// @Param request body model.EmailPasswordRequest true "Запрос на начало сброса пароля"
+// @Param request body model.ResetPasswordRequest true "Запрос на начало сброса пароля"
 // @Success 200 {object} model.Response "Сброс пароля начат"
 // @Failure 400 {object} model.Response "Ошибка в запросе"
 // @Router /password/reset/start [post]
 func (app *WebApp) HandleResetPasswordStarted(w http.ResponseWriter, r *http.Request) {
-	var req model.EmailPasswordRequest
+	var req model.ResetPasswordRequest
 
 	// Декодируем JSON из тела запроса в структуру
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -251,7 +253,7 @@ func (app *WebApp) HandleResetPasswordStarted(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	token, err := auth.ResetPassword(req.Email)
+	token, err := auth.ResetPassword(req.Email, req.ProofOfWork)
 	if err != nil {
 		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
 		return
@@ -455,6 +457,9 @@ func (app *WebApp) HandleGetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ETag позволяет клиенту сохранять пост условным запросом с заголовком If-Match.
+	// Ответ 304 отдается только на GET /posts/{slug}: на POST-запрос он не предусмотрен.
+	w.Header().Set("ETag", auth.PostETag(response.Post.ID, response.Post.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
@@ -505,8 +510,10 @@ func (app *WebApp) HandleDeletePost(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param request body model.UpdatePostRequest true "Запрос на обновление поста"
-// @Success 200 {object} model.Response "Пост успешно обновлен"
+// @Param If-Match header string false "ETag поста, полученный из /api/get-post"
+// @Success 200 {object} model.UpdatePostResponse "Пост успешно обновлен"
 // @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Failure 409 {object} model.UpdatePostConflictResponse "Пост изменен другим сохранением"
 // @Router /api/update-post [post]
 func (app *WebApp) HandleUpdatePost(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("authToken")
@@ -526,17 +533,46 @@ func (app *WebApp) HandleUpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Версия из заголовка If-Match имеет приоритет над версией в теле запроса
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := auth.ParsePostETag(ifMatch, req.Post.ID)
+		if err != nil {
+			http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+			return
+		}
+		req.Post.Version = version
+	}
+
 	response, err := auth.UpdatePost(token, req)
 	if err != nil {
 		log.App.Error(fmt.Sprintf("Ошибка при обновлении поста: %v", err)) // Логгируем ошибку
-		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		writePostError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", auth.PostETag(req.Post.ID, response.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+// writePostError отправляет ошибку сохранения поста. Конфликт версий возвращается с кодом 409
+// и актуальной версией поста, остальные ошибки - с кодом 400.
+func writePostError(w http.ResponseWriter, err error) {
+	var conflict *auth.PostConflictError
+	if !errors.As(err, &conflict) {
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", auth.PostETag(conflict.Current.ID, conflict.Current.Version))
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(model.UpdatePostConflictResponse{
+		Response: model.Response{Status: false, Message: conflict.Error()},
+		Post:     conflict.Current,
+	})
+}
+
 // HandleGetAllPosts обрабатывает запрос на получение всех постов
 // @Summary Получение всех постов
 // @Description Обрабатывает запрос на получение всех постов.
@@ -548,6 +584,12 @@ func (app *WebApp) HandleUpdatePost(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {object} model.Response "Ошибка в запросе"
 // @Router /api/get-all-posts [post]
 func (app *WebApp) HandleGetAllPosts(w http.ResponseWriter, r *http.Request) {
+	// Лента доступна и без авторизации, токен нужен только для реакций и закладок пользователя
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
 	var req model.GetAllPostsRequest
 
 	// Декодируем JSON из тела запроса в структуру
@@ -560,7 +602,7 @@ func (app *WebApp) HandleGetAllPosts(w http.ResponseWriter, r *http.Request) {
 
 	log.App.Info(fmt.Sprintf("Получен запрос на создание поста: %+v", req)) // Логгируем данные запроса
 
-	response, err := auth.GetAllPosts(req)
+	response, err := auth.GetAllPosts(token, req)
 	if err != nil {
 		log.App.Error(fmt.Sprintf("Ошибка при создании поста: %v", err)) // Логгируем ошибку
 		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
@@ -571,17 +613,23 @@ func (app *WebApp) HandleGetAllPosts(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// HandleGetAllMyPosts обрабатывает запрос на получение постов текущего пользователя
-// @Summary Получение постов текущего пользователя
-// @Description Обрабатывает запрос на получение всех постов текущего пользователя.
+// HandleGetAllMyPosts обрабатывает запрос на получение постов автора
+// @Summary Получение постов автора
+// @Description Возвращает посты автора с ID из запроса, при ID 0 - текущего пользователя. Свои посты видны все, у других авторов - только опубликованные. Авторизация необязательна.
 // @Tags posts
 // @Accept json
 // @Produce json
-// @Param request body model.GetAllPostsRequest true "Запрос на получение постов текущего пользователя"
+// @Param request body model.GetAllPostsRequest true "Запрос на получение постов автора"
 // @Success 200 {object} model.GetAllPostsResponse "Посты успешно получены"
 // @Failure 400 {object} model.Response "Ошибка в запросе"
 // @Router /api/get-all-my-posts [post]
 func (app *WebApp) HandleGetAllMyPosts(w http.ResponseWriter, r *http.Request) {
+	// Посты автора доступны и без авторизации, токен нужен для своих неопубликованных постов, реакций и закладок
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
 	var req model.GetAllPostsRequest
 
 	// Декодируем JSON из тела запроса в структуру
@@ -594,7 +642,7 @@ func (app *WebApp) HandleGetAllMyPosts(w http.ResponseWriter, r *http.Request) {
 
 	log.App.Info(fmt.Sprintf("Получен запрос на создание поста: %+v", req)) // Логгируем данные запроса
 
-	response, err := auth.GetAllMyPosts(req)
+	response, err := auth.GetAllMyPosts(token, req)
 	if err != nil {
 		log.App.Error(fmt.Sprintf("Ошибка при создании поста: %v", err)) // Логгируем ошибку
 		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
@@ -685,9 +733,10 @@ func (app *WebApp) HandleDownLike(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// HandleGetUserProfile обрабатывает запрос на получение профиля пользователя по ID
+// HandleGetUserProfile обрабатывает запрос на получение профиля пользователя по ID или имени пользователя
 // @Summary Получение профиля пользователя
-// @Description Обрабатывает запрос на получение профиля пользователя по ID.
+// @Description Возвращает имя пользователя, количество подписчиков и подписок. С токеном отмечается подписка на пользователя.
+// @Description Если ID не указан, пользователь ищется по имени для упоминаний.
 // @Tags user
 // @Accept json
 // @Produce json
@@ -696,6 +745,12 @@ func (app *WebApp) HandleDownLike(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {object} model.Response "Ошибка в запросе"
 // @Router /api/get-user-profile [post]
 func (app *WebApp) HandleGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	// Токен необязателен: без него профиль доступен без отметки подписки
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
 	var req model.ProfileRequest
 
 	// Декодируем JSON из тела запроса в структуру
@@ -706,20 +761,13 @@ func (app *WebApp) HandleGetUserProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Логика для получения профиля пользователя по ID
-	var user model.User
-	res := db.App.DB.First(&user, req.ID) // Предполагается, что у вас есть метод для получения пользователя
-	if res.Error != nil {
-		log.App.Error(r.RemoteAddr, " failed to get user profile: ", res.Error)
-		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: res.Error.Error()}), http.StatusBadRequest)
+	response, err := auth.GetUserProfile(token, req)
+	if err != nil {
+		log.App.Error(r.RemoteAddr, " failed to get user profile: ", err)
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
 		return
 	}
 
-	response := model.ProfileResponse{
-		Status: true,
-		Name:   user.Name,
-	}
-
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
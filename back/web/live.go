@@ -0,0 +1,122 @@
+package web
+
+import (
+	"app/auth"
+	"app/config"
+	"app/live"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	liveWriteWait      = 10 * time.Second // Время на отправку одного сообщения клиенту
+	liveMaxMessageSize = 4096             // Максимальный размер сообщения клиента в байтах
+)
+
+// HandleLive обрабатывает подключение к живым обновлениям по WebSocket
+// @Summary Живые обновления
+// @Description Открывает WebSocket, по которому приходят новые посты, изменения реакций постов, за которыми следит клиент,
+// @Description и новые уведомления. Клиент выбирает посты сообщениями {"action":"subscribe","postIds":[...]}.
+// @Tags live
+// @Success 101 "Соединение установлено"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/live [get]
+func (app *WebApp) HandleLive(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token, err := auth.ParseJWTToken(cookie.Value)
+	if err != nil {
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	// При ошибке Upgrade сам отвечает клиенту
+	conn, err := app.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.App.Error(r.RemoteAddr, " failed to upgrade live connection: ", err)
+		return
+	}
+
+	client := live.App.Register(token.UserId, 0, nil)
+	go writeLive(conn, client)
+	readLive(conn, client)
+}
+
+// readLive читает подписки клиента, пока соединение открыто. Соединение без ответа на проверку закрывается.
+func readLive(conn *websocket.Conn, client *live.Client) {
+	defer func() {
+		conn.Close()
+		live.App.Unregister(client)
+	}()
+
+	pongWait := 2 * livePingInterval()
+	conn.SetReadLimit(liveMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var req model.LiveRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.App.Error("Ошибка при чтении живых обновлений: ", err)
+			}
+			return
+		}
+
+		switch req.Action {
+		case model.LiveSubscribe:
+			client.Subscribe(req.PostIDs)
+		case model.LiveUnsubscribe:
+			client.Unsubscribe(req.PostIDs)
+		}
+	}
+}
+
+// writeLive отправляет клиенту события и проверки соединения. Отключенному клиенту соединение закрывается.
+func writeLive(conn *websocket.Conn, client *live.Client) {
+	ticker := time.NewTicker(livePingInterval())
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case event := <-client.Events():
+			conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-client.Done():
+			// Клиент отключен рассылкой, если не успевал забирать события
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Слишком медленное соединение"),
+				time.Now().Add(liveWriteWait))
+			return
+		}
+	}
+}
+
+// livePingInterval возвращает интервал проверки соединения
+func livePingInterval() time.Duration {
+	if seconds := config.File.LiveConfig.PingInterval; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 30 * time.Second
+}
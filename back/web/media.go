@@ -0,0 +1,185 @@
+package web
+
+import (
+	"app/auth"
+	"app/config"
+	"app/log"
+	"app/media"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleUploadMedia обрабатывает загрузку файла
+// @Summary Загрузка файла
+// @Description Загружает изображение в библиотеку пользователя. Тип файла определяется по содержимому, размер и общий объем файлов пользователя ограничены. В текст поста файл вставляется разметкой ![описание](media:ID) из поля markdown ответа.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Изображение"
+// @Success 200 {object} model.UploadMediaResponse "Файл загружен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/upload-media [post]
+func (app *WebApp) HandleUploadMedia(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	// Тело запроса ограничено, чтобы слишком большой файл не читался целиком
+	maxSize := int64(config.File.MediaConfig.MaxSize) << 20
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при чтении загружаемого файла: %v", err)) // Логгируем ошибку
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: fmt.Sprintf("Файл больше %d МБ", config.File.MediaConfig.MaxSize)}), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось прочитать файл: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при чтении загружаемого файла: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось прочитать файл: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UploadMedia(token, header.Filename, data)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при загрузке файла: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetMediaLibrary обрабатывает запрос на получение библиотеки файлов
+// @Summary Библиотека файлов
+// @Description Возвращает страницу файлов пользователя начиная с последних загруженных, количество постов, ссылающихся на каждый файл, и занятую квоту.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param request body model.GetMediaLibraryRequest true "Запрос на получение библиотеки файлов"
+// @Success 200 {object} model.GetMediaLibraryResponse "Файлы получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-media-library [post]
+func (app *WebApp) HandleGetMediaLibrary(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetMediaLibraryRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetMediaLibrary(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении библиотеки файлов: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteMedia обрабатывает запрос на удаление файла
+// @Summary Удаление файла
+// @Description Удаляет файл из библиотеки пользователя. Файл, на который ссылается хотя бы один пост, в том числе из корзины, удалить нельзя.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteMediaRequest true "Запрос на удаление файла"
+// @Success 200 {object} model.DeleteMediaResponse "Файл удален"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/delete-media [post]
+func (app *WebApp) HandleDeleteMedia(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.DeleteMediaRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.DeleteMedia(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при удалении файла: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetMedia отдает загруженный файл
+// @Summary Загруженный файл
+// @Description Отдает файл из хранилища. Авторизация не требуется: файлы вставляются в посты.
+// @Tags media
+// @Produce image/jpeg,image/png,image/gif,image/webp
+// @Param key path string true "Имя файла в хранилище"
+// @Success 200 {file} file "Файл"
+// @Failure 404 {object} model.Response "Файл не найден"
+// @Router /media/{key} [get]
+func (app *WebApp) HandleGetMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	file, reader, err := auth.GetMediaFile(key)
+	if errors.Is(err, media.ErrNotFound) {
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Файл не найден"}), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении файла %q: %v", key, err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось получить файл"}), http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	// Имя файла случайное и не меняется, поэтому файл можно кешировать сколько угодно.
+	// Браузер не должен угадывать тип по содержимому.
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", fmt.Sprint(file.Size))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, reader)
+}
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleGetNotifications обрабатывает запрос на получение уведомлений
+// @Summary Уведомления
+// @Description Возвращает страницу уведомлений пользователя, начиная с последних событий. Лайки одного поста собираются в одно уведомление.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body model.GetNotificationsRequest true "Запрос на получение уведомлений"
+// @Success 200 {object} model.GetNotificationsResponse "Уведомления получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-notifications [post]
+func (app *WebApp) HandleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetNotificationsRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetNotifications(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении уведомлений: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleMarkNotificationRead обрабатывает отметку уведомления прочитанным
+// @Summary Отметка уведомления
+// @Description Отмечает уведомление прочитанным.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body model.MarkNotificationReadRequest true "Запрос на отметку уведомления"
+// @Success 200 {object} model.MarkNotificationsReadResponse "Уведомление прочитано"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/mark-notification-read [post]
+func (app *WebApp) HandleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.MarkNotificationReadRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.MarkNotificationRead(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отметке уведомления: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleMarkAllNotificationsRead обрабатывает отметку всех уведомлений прочитанными
+// @Summary Отметка всех уведомлений
+// @Description Отмечает прочитанными все уведомления пользователя.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} model.MarkNotificationsReadResponse "Все уведомления прочитаны"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/mark-all-notifications-read [post]
+func (app *WebApp) HandleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.MarkAllNotificationsRead(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отметке уведомлений: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetUnreadNotificationsCount обрабатывает запрос на получение количества непрочитанных уведомлений
+// @Summary Непрочитанные уведомления
+// @Description Возвращает количество непрочитанных уведомлений пользователя.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} model.GetUnreadNotificationsCountResponse "Количество непрочитанных уведомлений получено"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-unread-notifications-count [post]
+func (app *WebApp) HandleGetUnreadNotificationsCount(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetUnreadNotificationsCount(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении количества непрочитанных уведомлений: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
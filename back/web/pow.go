@@ -0,0 +1,43 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandlePowChallenge обрабатывает запрос на получение задачи proof-of-work
+// @Summary Задача proof-of-work
+// @Description Выдает подписанную задачу для регистрации (register) или сброса пароля (reset-password). Клиент подбирает решение, при котором SHA-256 от строки "задача:почта:решение" (почта в нижнем регистре) начинается с difficulty нулевых бит, и передает задачу и решение в полях powChallenge и powSolution. Сложность растет при большом количестве запросов. Если задачи отключены, enabled равно false.
+// @Tags pow
+// @Accept json
+// @Produce json
+// @Param request body model.PowChallengeRequest true "Запрос на получение задачи"
+// @Success 200 {object} model.PowChallengeResponse "Задача выдана"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/pow-challenge [post]
+func (app *WebApp) HandlePowChallenge(w http.ResponseWriter, r *http.Request) {
+	var req model.PowChallengeRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetPowChallenge(req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при выдаче задачи proof-of-work: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
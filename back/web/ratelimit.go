@@ -0,0 +1,68 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/ratelimit"
+	"app/utils"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// limit ограничивает частоту запросов к обработчику next по правилам группы маршрутов group.
+// Превысившему ограничение клиенту отвечает 429 с заголовком Retry-After.
+func (app *WebApp) limit(group string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limiter := ratelimit.App
+		if limiter == nil {
+			next(w, r)
+			return
+		}
+
+		ip := limiter.ClientIP(r)
+		if limiter.Allowed(ip) {
+			next(w, r)
+			return
+		}
+
+		var userID uint
+		if cookie, err := r.Cookie("authToken"); err == nil {
+			userID, _ = auth.TokenUserID(cookie.Value)
+		}
+
+		result, limited, err := limiter.Take(group, ip, userID)
+		if err != nil {
+			// Недоступное хранилище не должно останавливать приложение, поэтому запрос пропускается
+			log.App.Error(fmt.Sprintf("Ошибка при проверке частоты запросов: %v", err)) // Логгируем ошибку
+			next(w, r)
+			return
+		}
+		if !limited {
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			log.App.Info(fmt.Sprintf("Превышена частота запросов %s: %s, пользователь %d", group, ip, userID))
+			http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: fmt.Sprintf("Слишком много запросов, повторите через %d с", retryAfter)}), http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandlePutReaction обрабатывает постановку реакции на пост
+// @Summary Постановка реакции
+// @Description Ставит реакцию указанного вида на пост и возвращает текущие реакции поста.
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Param request body model.ReactionRequest true "Запрос на постановку реакции"
+// @Success 200 {object} model.ReactionResponse "Реакция поставлена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/put-reaction [post]
+func (app *WebApp) HandlePutReaction(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ReactionRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.PutReaction(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при постановке реакции: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDownReaction обрабатывает снятие реакции с поста
+// @Summary Снятие реакции
+// @Description Снимает реакцию указанного вида с поста и возвращает текущие реакции поста.
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Param request body model.ReactionRequest true "Запрос на снятие реакции"
+// @Success 200 {object} model.ReactionResponse "Реакция снята"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/down-reaction [post]
+func (app *WebApp) HandleDownReaction(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ReactionRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.DownReaction(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при снятии реакции: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetReactionKinds обрабатывает запрос на получение доступных видов реакций
+// @Summary Виды реакций
+// @Description Возвращает виды реакций, которые можно поставить посту.
+// @Tags reactions
+// @Produce json
+// @Success 200 {object} model.GetReactionKindsResponse "Виды реакций получены"
+// @Router /api/get-reaction-kinds [post]
+func (app *WebApp) HandleGetReactionKinds(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(auth.GetReactionKinds())
+}
@@ -0,0 +1,183 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleReportContent обрабатывает запрос на жалобу
+// @Summary Жалоба на пост, комментарий или пользователя
+// @Description Жалобы на один объект собираются в одну запись очереди модерации, пока она не рассмотрена. Повторная жалоба того же пользователя заменяет причину и пояснение. На свои посты, комментарии и на себя пожаловаться нельзя.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param request body model.ReportContentRequest true "Запрос на жалобу"
+// @Success 200 {object} model.ReportContentResponse "Жалоба отправлена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/report-content [post]
+func (app *WebApp) HandleReportContent(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ReportContentRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.ReportContent(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отправке жалобы: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetReportReasons обрабатывает запрос на получение причин жалоб
+// @Summary Причины жалоб
+// @Description Возвращает допустимые причины жалоб в порядке показа. Для причины other нужно пояснение.
+// @Tags report
+// @Produce json
+// @Success 200 {object} model.GetReportReasonsResponse "Причины жалоб получены"
+// @Router /api/get-report-reasons [post]
+func (app *WebApp) HandleGetReportReasons(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(auth.GetReportReasons())
+}
+
+// HandleGetReportQueue обрабатывает запрос на получение очереди жалоб
+// @Summary Очередь жалоб
+// @Description Открытые жалобы упорядочены по количеству пожаловавшихся пользователей, рассмотренные - начиная с последних. Можно отфильтровать по статусу, объекту, причине и назначенному модератору. Доступно администраторам и модераторам.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param request body model.GetReportQueueRequest true "Запрос на получение очереди жалоб"
+// @Success 200 {object} model.GetReportQueueResponse "Очередь жалоб получена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-report-queue [post]
+func (app *WebApp) HandleGetReportQueue(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetReportQueueRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetReportQueue(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении очереди жалоб: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAssignReport обрабатывает запрос на назначение жалобы модератору
+// @Summary Назначение жалобы
+// @Description Назначает открытую жалобу администратору или модератору. assigneeId 0 снимает назначение. Доступно администраторам и модераторам.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param request body model.AssignReportRequest true "Запрос на назначение жалобы"
+// @Success 200 {object} model.AssignReportResponse "Жалоба назначена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/assign-report [post]
+func (app *WebApp) HandleAssignReport(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.AssignReportRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.AssignReport(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при назначении жалобы: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleResolveReport обрабатывает запрос на решение по жалобе
+// @Summary Решение по жалобе
+// @Description dismiss отклоняет жалобу, hide скрывает пост или комментарий, delete удаляет его, suspend блокирует автора на suspendDays дней. Пожаловавшиеся пользователи получают уведомление о решении. Доступно администраторам и модераторам.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param request body model.ResolveReportRequest true "Запрос на решение по жалобе"
+// @Success 200 {object} model.ResolveReportResponse "Решение по жалобе принято"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/resolve-report [post]
+func (app *WebApp) HandleResolveReport(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.ResolveReportRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.ResolveReport(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при рассмотрении жалобы: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleGetPostRevisions обрабатывает запрос на получение истории ревизий поста
+// @Summary Получение ревизий поста
+// @Description Возвращает список сохраненных ревизий поста. Доступно автору поста и администратору.
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param request body model.GetPostRevisionsRequest true "Запрос на получение ревизий"
+// @Success 200 {object} model.GetPostRevisionsResponse "Ревизии получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-post-revisions [post]
+func (app *WebApp) HandleGetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetPostRevisionsRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetPostRevisions(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении ревизий поста: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetRevisionDiff обрабатывает запрос на сравнение двух ревизий поста
+// @Summary Сравнение ревизий поста
+// @Description Возвращает построчное сравнение заголовка, подзаголовка, содержания и тегов двух ревизий поста.
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param request body model.GetRevisionDiffRequest true "Запрос на сравнение ревизий"
+// @Success 200 {object} model.GetRevisionDiffResponse "Сравнение получено"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-revision-diff [post]
+func (app *WebApp) HandleGetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetRevisionDiffRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetRevisionDiff(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при сравнении ревизий: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRestorePostRevision обрабатывает восстановление старой ревизии поста
+// @Summary Восстановление ревизии поста
+// @Description Восстанавливает содержимое поста из указанной ревизии. Восстановление сохраняется как новая ревизия.
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param request body model.RestoreRevisionRequest true "Запрос на восстановление ревизии"
+// @Success 200 {object} model.RestoreRevisionResponse "Ревизия восстановлена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Failure 409 {object} model.UpdatePostConflictResponse "Пост изменен другим сохранением"
+// @Router /api/restore-post-revision [post]
+func (app *WebApp) HandleRestorePostRevision(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestoreRevisionRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.RestorePostRevision(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при восстановлении ревизии: %v", err)) // Логгируем ошибку
+		writePostError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", auth.PostETag(req.PostID, response.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
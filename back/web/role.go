@@ -0,0 +1,51 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleSetUserRole обрабатывает назначение и снятие модератора
+// @Summary Назначение модератора
+// @Description Назначает пользователя модератором (role moderator) или снимает роль (role user). Роль администратора изменить нельзя. Открытые жалобы снятого модератора возвращаются в общую очередь. Доступно администраторам.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param request body model.SetUserRoleRequest true "Запрос на изменение роли"
+// @Success 200 {object} model.SetUserRoleResponse "Роль изменена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/set-user-role [post]
+func (app *WebApp) HandleSetUserRole(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.SetUserRoleRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.SetUserRole(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении роли пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
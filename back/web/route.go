@@ -1,6 +1,7 @@
 package web
 
 import (
+	"app/model"
 	"log"
 	"net/http"
 
@@ -14,33 +15,114 @@ func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // SetRoutes устанавливает маршруты для HTTP-сервера.
-// Эта функция связывает URL-пути с обработчиками.
+// Эта функция связывает URL-пути с обработчиками. Каждый маршрут относится к группе с общим ограничением частоты запросов:
+// авторизация, изменение или чтение данных.
 func (app *WebApp) SetRoutes() {
 	// Маршрут для WebSocket соединения
+	app.Router.HandleFunc("/api/live", app.limit(model.RateLimitRead, app.HandleLive)).Methods("GET")
+	app.Router.HandleFunc("/api/live-events", app.limit(model.RateLimitRead, app.HandleLiveEvents)).Methods("GET")
 
-	app.Router.HandleFunc("/api/reg", app.HandleRegistrationStarted).Methods("POST")
-	app.Router.HandleFunc("/api/code-confirm", app.HandleRegistrationConfirmation).Methods("POST")
+	app.Router.HandleFunc("/api/pow-challenge", app.limit(model.RateLimitAuth, app.HandlePowChallenge)).Methods("POST")
+	app.Router.HandleFunc("/api/reg", app.limit(model.RateLimitAuth, app.HandleRegistrationStarted)).Methods("POST")
+	app.Router.HandleFunc("/api/code-confirm", app.limit(model.RateLimitAuth, app.HandleRegistrationConfirmation)).Methods("POST")
 
-	app.Router.HandleFunc("/api/login", app.HandleLogin).Methods("POST")
-	app.Router.HandleFunc("/api/jwt-login", app.HandleJwtLogin).Methods("POST")
+	app.Router.HandleFunc("/api/login", app.limit(model.RateLimitAuth, app.HandleLogin)).Methods("POST")
+	app.Router.HandleFunc("/api/jwt-login", app.limit(model.RateLimitRead, app.HandleJwtLogin)).Methods("POST")
 
-	app.Router.HandleFunc("/api/logout", app.HandleLogout).Methods("POST")
+	app.Router.HandleFunc("/api/logout", app.limit(model.RateLimitWrite, app.HandleLogout)).Methods("POST")
 
-	app.Router.HandleFunc("/api/new-post", app.HandleNewPost).Methods("POST")
+	app.Router.HandleFunc("/api/new-post", app.limit(model.RateLimitWrite, app.HandleNewPost)).Methods("POST")
 
-	app.Router.HandleFunc("/api/get-post", app.HandleGetPost).Methods("POST")
-	app.Router.HandleFunc("/api/delete-post", app.HandleDeletePost).Methods("POST")
-	app.Router.HandleFunc("/api/update-post", app.HandleUpdatePost).Methods("POST")
+	app.Router.HandleFunc("/api/get-post", app.limit(model.RateLimitRead, app.HandleGetPost)).Methods("POST")
+	app.Router.HandleFunc("/api/delete-post", app.limit(model.RateLimitWrite, app.HandleDeletePost)).Methods("POST")
+	app.Router.HandleFunc("/api/update-post", app.limit(model.RateLimitWrite, app.HandleUpdatePost)).Methods("POST")
 
-	app.Router.HandleFunc("/api/get-all-posts", app.HandleGetAllPosts).Methods("POST")
-	app.Router.HandleFunc("/api/get-all-my-posts", app.HandleGetAllMyPosts).Methods("POST")
+	app.Router.HandleFunc("/posts/{slug}", app.limit(model.RateLimitRead, app.HandleGetPostBySlug)).Methods("GET")
 
-	app.Router.HandleFunc("/api/put-like", app.HandlePutLike).Methods("POST")
-	app.Router.HandleFunc("/api/down-like", app.HandleDownLike).Methods("POST")
+	app.Router.HandleFunc("/api/get-post-revisions", app.limit(model.RateLimitRead, app.HandleGetPostRevisions)).Methods("POST")
+	app.Router.HandleFunc("/api/get-revision-diff", app.limit(model.RateLimitRead, app.HandleGetRevisionDiff)).Methods("POST")
+	app.Router.HandleFunc("/api/restore-post-revision", app.limit(model.RateLimitWrite, app.HandleRestorePostRevision)).Methods("POST")
 
-	app.Router.HandleFunc("/api/get-user-profile", app.HandleGetUserProfile).Methods("POST")
+	app.Router.HandleFunc("/api/get-trash", app.limit(model.RateLimitRead, app.HandleGetTrash)).Methods("POST")
+	app.Router.HandleFunc("/api/restore-post", app.limit(model.RateLimitWrite, app.HandleRestorePost)).Methods("POST")
 
-	app.Router.HandleFunc("/api/set-password", app.HandleSetPassword).Methods("POST")
+	app.Router.HandleFunc("/api/get-comments", app.limit(model.RateLimitRead, app.HandleGetComments)).Methods("POST")
+	app.Router.HandleFunc("/api/new-comment", app.limit(model.RateLimitWrite, app.HandleNewComment)).Methods("POST")
+	app.Router.HandleFunc("/api/update-comment", app.limit(model.RateLimitWrite, app.HandleUpdateComment)).Methods("POST")
+	app.Router.HandleFunc("/api/delete-comment", app.limit(model.RateLimitWrite, app.HandleDeleteComment)).Methods("POST")
+
+	app.Router.HandleFunc("/api/set-comment-mode", app.limit(model.RateLimitWrite, app.HandleSetCommentMode)).Methods("POST")
+	app.Router.HandleFunc("/api/get-comment-queue", app.limit(model.RateLimitRead, app.HandleGetCommentQueue)).Methods("POST")
+	app.Router.HandleFunc("/api/moderate-comment", app.limit(model.RateLimitWrite, app.HandleModerateComment)).Methods("POST")
+	app.Router.HandleFunc("/api/lock-comment-thread", app.limit(model.RateLimitWrite, app.HandleLockCommentThread)).Methods("POST")
+	app.Router.HandleFunc("/api/ban-commenter", app.limit(model.RateLimitWrite, app.HandleBanCommenter)).Methods("POST")
+	app.Router.HandleFunc("/api/unban-commenter", app.limit(model.RateLimitWrite, app.HandleUnbanCommenter)).Methods("POST")
+	app.Router.HandleFunc("/api/get-comment-bans", app.limit(model.RateLimitRead, app.HandleGetCommentBans)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-tags", app.limit(model.RateLimitRead, app.HandleGetTags)).Methods("POST")
+	app.Router.HandleFunc("/api/update-tag", app.limit(model.RateLimitWrite, app.HandleUpdateTag)).Methods("POST")
+	app.Router.HandleFunc("/api/merge-tags", app.limit(model.RateLimitWrite, app.HandleMergeTags)).Methods("POST")
+	app.Router.HandleFunc("/api/delete-unused-tags", app.limit(model.RateLimitWrite, app.HandleDeleteUnusedTags)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-tag-posts", app.limit(model.RateLimitRead, app.HandleGetTagPosts)).Methods("POST")
+	app.Router.HandleFunc("/api/get-followed-tags", app.limit(model.RateLimitRead, app.HandleGetFollowedTags)).Methods("POST")
+	app.Router.HandleFunc("/api/get-followed-tags-feed", app.limit(model.RateLimitRead, app.HandleGetFollowedTagsFeed)).Methods("POST")
+	app.Router.HandleFunc("/api/follow-tag", app.limit(model.RateLimitWrite, app.HandleFollowTag)).Methods("POST")
+	app.Router.HandleFunc("/api/unfollow-tag", app.limit(model.RateLimitWrite, app.HandleUnfollowTag)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-all-posts", app.limit(model.RateLimitRead, app.HandleGetAllPosts)).Methods("POST")
+	app.Router.HandleFunc("/api/get-all-my-posts", app.limit(model.RateLimitRead, app.HandleGetAllMyPosts)).Methods("POST")
+
+	app.Router.HandleFunc("/api/put-like", app.limit(model.RateLimitWrite, app.HandlePutLike)).Methods("POST")
+	app.Router.HandleFunc("/api/down-like", app.limit(model.RateLimitWrite, app.HandleDownLike)).Methods("POST")
+	app.Router.HandleFunc("/api/put-reaction", app.limit(model.RateLimitWrite, app.HandlePutReaction)).Methods("POST")
+	app.Router.HandleFunc("/api/down-reaction", app.limit(model.RateLimitWrite, app.HandleDownReaction)).Methods("POST")
+	app.Router.HandleFunc("/api/get-reaction-kinds", app.limit(model.RateLimitRead, app.HandleGetReactionKinds)).Methods("POST")
+
+	app.Router.HandleFunc("/api/add-bookmark", app.limit(model.RateLimitWrite, app.HandleAddBookmark)).Methods("POST")
+	app.Router.HandleFunc("/api/remove-bookmark", app.limit(model.RateLimitWrite, app.HandleRemoveBookmark)).Methods("POST")
+	app.Router.HandleFunc("/api/create-reading-list", app.limit(model.RateLimitWrite, app.HandleCreateReadingList)).Methods("POST")
+	app.Router.HandleFunc("/api/update-reading-list", app.limit(model.RateLimitWrite, app.HandleUpdateReadingList)).Methods("POST")
+	app.Router.HandleFunc("/api/delete-reading-list", app.limit(model.RateLimitWrite, app.HandleDeleteReadingList)).Methods("POST")
+	app.Router.HandleFunc("/api/reorder-reading-list", app.limit(model.RateLimitWrite, app.HandleReorderReadingList)).Methods("POST")
+	app.Router.HandleFunc("/api/get-reading-lists", app.limit(model.RateLimitRead, app.HandleGetReadingLists)).Methods("POST")
+	app.Router.HandleFunc("/api/get-reading-list", app.limit(model.RateLimitRead, app.HandleGetReadingList)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-user-profile", app.limit(model.RateLimitRead, app.HandleGetUserProfile)).Methods("POST")
+	app.Router.HandleFunc("/api/set-username", app.limit(model.RateLimitWrite, app.HandleSetUsername)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-notifications", app.limit(model.RateLimitRead, app.HandleGetNotifications)).Methods("POST")
+	app.Router.HandleFunc("/api/mark-notification-read", app.limit(model.RateLimitWrite, app.HandleMarkNotificationRead)).Methods("POST")
+	app.Router.HandleFunc("/api/mark-all-notifications-read", app.limit(model.RateLimitWrite, app.HandleMarkAllNotificationsRead)).Methods("POST")
+	app.Router.HandleFunc("/api/get-unread-notifications-count", app.limit(model.RateLimitRead, app.HandleGetUnreadNotificationsCount)).Methods("POST")
+
+	app.Router.HandleFunc("/api/follow-user", app.limit(model.RateLimitWrite, app.HandleFollowUser)).Methods("POST")
+	app.Router.HandleFunc("/api/unfollow-user", app.limit(model.RateLimitWrite, app.HandleUnfollowUser)).Methods("POST")
+	app.Router.HandleFunc("/api/get-following-feed", app.limit(model.RateLimitRead, app.HandleGetFollowingFeed)).Methods("POST")
+
+	app.Router.HandleFunc("/api/block-user", app.limit(model.RateLimitWrite, app.HandleBlockUser)).Methods("POST")
+	app.Router.HandleFunc("/api/unblock-user", app.limit(model.RateLimitWrite, app.HandleUnblockUser)).Methods("POST")
+	app.Router.HandleFunc("/api/mute-user", app.limit(model.RateLimitWrite, app.HandleMuteUser)).Methods("POST")
+	app.Router.HandleFunc("/api/unmute-user", app.limit(model.RateLimitWrite, app.HandleUnmuteUser)).Methods("POST")
+	app.Router.HandleFunc("/api/get-blocked-users", app.limit(model.RateLimitRead, app.HandleGetBlockedUsers)).Methods("POST")
+	app.Router.HandleFunc("/api/get-muted-users", app.limit(model.RateLimitRead, app.HandleGetMutedUsers)).Methods("POST")
+
+	app.Router.HandleFunc("/api/report-content", app.limit(model.RateLimitWrite, app.HandleReportContent)).Methods("POST")
+	app.Router.HandleFunc("/api/get-report-reasons", app.limit(model.RateLimitRead, app.HandleGetReportReasons)).Methods("POST")
+	app.Router.HandleFunc("/api/get-report-queue", app.limit(model.RateLimitRead, app.HandleGetReportQueue)).Methods("POST")
+	app.Router.HandleFunc("/api/assign-report", app.limit(model.RateLimitWrite, app.HandleAssignReport)).Methods("POST")
+	app.Router.HandleFunc("/api/resolve-report", app.limit(model.RateLimitWrite, app.HandleResolveReport)).Methods("POST")
+	app.Router.HandleFunc("/api/set-user-role", app.limit(model.RateLimitWrite, app.HandleSetUserRole)).Methods("POST")
+
+	app.Router.HandleFunc("/api/get-filter-rules", app.limit(model.RateLimitRead, app.HandleGetFilterRules)).Methods("POST")
+	app.Router.HandleFunc("/api/update-filter-rules", app.limit(model.RateLimitWrite, app.HandleUpdateFilterRules)).Methods("POST")
+
+	app.Router.HandleFunc("/api/upload-media", app.limit(model.RateLimitWrite, app.HandleUploadMedia)).Methods("POST")
+	app.Router.HandleFunc("/api/get-media-library", app.limit(model.RateLimitRead, app.HandleGetMediaLibrary)).Methods("POST")
+	app.Router.HandleFunc("/api/delete-media", app.limit(model.RateLimitWrite, app.HandleDeleteMedia)).Methods("POST")
+	app.Router.HandleFunc("/media/{key}", app.limit(model.RateLimitRead, app.HandleGetMedia)).Methods("GET")
+
+	app.Router.HandleFunc("/api/set-password", app.limit(model.RateLimitAuth, app.HandleSetPassword)).Methods("POST")
 
 	// Добавляем маршрут для Swagger
 	app.Router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
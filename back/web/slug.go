@@ -0,0 +1,75 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleGetPostBySlug обрабатывает получение поста по постоянной ссылке
+// @Summary Получение поста по slug
+// @Description Возвращает пост по slug. Авторизация необязательна. Прежний slug поста перенаправляет на текущий.
+// @Tags posts
+// @Produce json
+// @Param slug path string true "Slug поста"
+// @Param If-None-Match header string false "ETag ранее полученной версии поста, можно несколько через запятую"
+// @Success 200 {object} model.GetPostResponse "Пост успешно получен"
+// @Success 304 "Пост не изменился"
+// @Success 301 "Slug устарел, перенаправление на текущий"
+// @Failure 404 {object} model.Response "Пост не найден"
+// @Router /posts/{slug} [get]
+func (app *WebApp) HandleGetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	// Пост по ссылке доступен и без авторизации
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
+	response, currentSlug, err := auth.GetPostBySlug(token, slug)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении поста по slug %q: %v", slug, err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusNotFound)
+		return
+	}
+	if currentSlug != "" {
+		http.Redirect(w, r, "/posts/"+currentSlug, http.StatusMovedPermanently)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(response); err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при формировании ответа: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Ошибка при формировании ответа"}), http.StatusInternalServerError)
+		return
+	}
+
+	// Версия поста не меняется при реакциях и комментариях, а ответ зависит еще и от читателя,
+	// поэтому ETag строится по самому ответу
+	etag := contentETag(body.Bytes())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Cookie")
+	if auth.MatchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// contentETag возвращает ETag, построенный по хешу тела ответа
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"app/auth"
+	"app/live"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandleLiveEvents обрабатывает подключение к живым обновлениям через Server-Sent Events
+// @Summary Живые обновления через SSE
+// @Description Поток тех же событий, что и в /api/live, для клиентов, у которых не работает WebSocket.
+// @Description Посты, за реакциями которых следит клиент, передаются параметром posts через запятую.
+// @Description После переподключения пропущенные события досылаются по заголовку Last-Event-ID или параметру lastEventId;
+// @Description если они уже не хранятся, приходит событие reset, после которого данные нужно загрузить заново.
+// @Tags live
+// @Produce text/event-stream
+// @Param posts query string false "ID постов через запятую"
+// @Param lastEventId query int false "Номер последнего полученного события"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/live-events [get]
+func (app *WebApp) HandleLiveEvents(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token, err := auth.ParseJWTToken(cookie.Value)
+	if err != nil {
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	postIDs, err := parseIDList(r.URL.Query().Get("posts"))
+	if err != nil {
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	// Браузер сам передает Last-Event-ID при переподключении, параметр нужен для первого подключения после перезагрузки страницы
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Недопустимый номер события"}), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Отключает буферизацию ответа в nginx
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	if err := rc.Flush(); err != nil {
+		log.App.Error(r.RemoteAddr, " failed to flush live events: ", err)
+		return
+	}
+
+	client := live.App.Register(token.UserId, lastID, postIDs)
+	defer live.App.Unregister(client)
+
+	// Комментарии не дают прокси закрыть соединение, по которому давно не было событий
+	ticker := time.NewTicker(livePingInterval())
+	defer ticker.Stop()
+
+	for {
+		var message string
+		select {
+		case event := <-client.Events():
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				log.App.Error("Ошибка при отправке живых обновлений: ", err)
+				continue
+			}
+			message = fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-ticker.C:
+			message = ": ping\n\n"
+		case <-client.Done():
+			// Клиент отключен рассылкой, если не успевал забирать события; браузер переподключится с Last-Event-ID
+			return
+		case <-r.Context().Done():
+			return
+		}
+
+		rc.SetWriteDeadline(time.Now().Add(liveWriteWait))
+		if _, err := fmt.Fprint(w, message); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// parseIDList разбирает список ID через запятую
+func parseIDList(list string) ([]uint, error) {
+	var ids []uint
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Недопустимый ID поста: %s", item)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
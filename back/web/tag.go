@@ -0,0 +1,325 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleGetTags обрабатывает запрос на получение списка тегов
+// @Summary Получение тегов
+// @Description Возвращает все теги с описаниями и количеством постов, начиная с самых популярных.
+// @Tags tags
+// @Produce json
+// @Success 200 {object} model.GetTagsResponse "Теги получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-tags [post]
+func (app *WebApp) HandleGetTags(w http.ResponseWriter, r *http.Request) {
+	response, err := auth.GetTags()
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении тегов: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateTag обрабатывает переименование тега и изменение его описания
+// @Summary Изменение тега
+// @Description Переименовывает тег и меняет его описание. Доступно администратору.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateTagRequest true "Запрос на изменение тега"
+// @Success 200 {object} model.UpdateTagResponse "Тег обновлен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/update-tag [post]
+func (app *WebApp) HandleUpdateTag(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.UpdateTagRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UpdateTag(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при изменении тега: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleMergeTags обрабатывает объединение тегов
+// @Summary Объединение тегов
+// @Description Переносит посты указанных тегов в целевой тег и удаляет объединенные теги. Доступно администратору.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.MergeTagsRequest true "Запрос на объединение тегов"
+// @Success 200 {object} model.MergeTagsResponse "Теги объединены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/merge-tags [post]
+func (app *WebApp) HandleMergeTags(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.MergeTagsRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.MergeTags(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при объединении тегов: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteUnusedTags обрабатывает удаление неиспользуемых тегов
+// @Summary Удаление неиспользуемых тегов
+// @Description Удаляет теги, которые не стоят ни у одного поста. Доступно администратору.
+// @Tags tags
+// @Produce json
+// @Success 200 {object} model.DeleteUnusedTagsResponse "Неиспользуемые теги удалены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/delete-unused-tags [post]
+func (app *WebApp) HandleDeleteUnusedTags(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.DeleteUnusedTags(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при удалении неиспользуемых тегов: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetTagPosts обрабатывает запрос на получение постов тега
+// @Summary Получение постов тега
+// @Description Возвращает страницу постов тега, отсортированных по дате или лайкам. Авторизация необязательна.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.GetTagPostsRequest true "Запрос на получение постов тега"
+// @Success 200 {object} model.GetTagPostsResponse "Посты тега получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-tag-posts [post]
+func (app *WebApp) HandleGetTagPosts(w http.ResponseWriter, r *http.Request) {
+	// Посты тега доступны и без авторизации
+	token := ""
+	if cookie, err := r.Cookie("authToken"); err == nil {
+		token = cookie.Value
+	}
+
+	var req model.GetTagPostsRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetTagPosts(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении постов тега: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetFollowedTagsFeed обрабатывает запрос на получение ленты по подпискам на теги
+// @Summary Лента подписок на теги
+// @Description Возвращает страницу постов со всех тегов, на которые подписан пользователь.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.GetFollowedTagsFeedRequest true "Запрос на получение ленты подписок"
+// @Success 200 {object} model.GetFollowedTagsFeedResponse "Лента подписок получена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-followed-tags-feed [post]
+func (app *WebApp) HandleGetFollowedTagsFeed(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.GetFollowedTagsFeedRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.GetFollowedTagsFeed(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении ленты подписок: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetFollowedTags обрабатывает запрос на получение подписок на теги
+// @Summary Подписки на теги
+// @Description Возвращает теги, на которые подписан пользователь.
+// @Tags tags
+// @Produce json
+// @Success 200 {object} model.GetFollowedTagsResponse "Подписки получены"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-followed-tags [post]
+func (app *WebApp) HandleGetFollowedTags(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetFollowedTags(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении подписок на теги: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleFollowTag обрабатывает подписку на тег
+// @Summary Подписка на тег
+// @Description Подписывает пользователя на тег. Посты тега попадают в ленту подписок и поднимаются в общей ленте.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.FollowTagRequest true "Запрос на подписку"
+// @Success 200 {object} model.FollowTagResponse "Вы подписались на тег"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/follow-tag [post]
+func (app *WebApp) HandleFollowTag(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.FollowTagRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.FollowTag(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при подписке на тег: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleUnfollowTag обрабатывает отписку от тега
+// @Summary Отписка от тега
+// @Description Отписывает пользователя от тега.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body model.FollowTagRequest true "Запрос на отписку"
+// @Success 200 {object} model.FollowTagResponse "Вы отписались от тега"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/unfollow-tag [post]
+func (app *WebApp) HandleUnfollowTag(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.FollowTagRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.UnfollowTag(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при отписке от тега: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
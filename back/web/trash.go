@@ -0,0 +1,79 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleGetTrash обрабатывает запрос на получение корзины удаленных постов
+// @Summary Получение корзины
+// @Description Возвращает удаленные посты пользователя. Администратор получает все удаленные посты.
+// @Tags trash
+// @Produce json
+// @Success 200 {object} model.GetTrashResponse "Корзина получена"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/get-trash [post]
+func (app *WebApp) HandleGetTrash(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	response, err := auth.GetTrash(token)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при получении корзины: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRestorePost обрабатывает восстановление поста из корзины
+// @Summary Восстановление поста из корзины
+// @Description Восстанавливает удаленный пост вместе с тегами и лайками. Доступно автору поста и администратору.
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param request body model.RestorePostRequest true "Запрос на восстановление поста"
+// @Success 200 {object} model.RestorePostResponse "Пост восстановлен"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/restore-post [post]
+func (app *WebApp) HandleRestorePost(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.RestorePostRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.RestorePost(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при восстановлении поста: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
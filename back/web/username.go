@@ -0,0 +1,51 @@
+package web
+
+import (
+	"app/auth"
+	"app/log"
+	"app/model"
+	"app/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HandleSetUsername обрабатывает запрос на смену имени пользователя
+// @Summary Смена имени пользователя
+// @Description Меняет уникальное имя, по которому пользователя упоминают в постах и комментариях (@username). Имя приводится к нижнему регистру.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body model.SetUsernameRequest true "Запрос на смену имени пользователя"
+// @Success 200 {object} model.SetUsernameResponse "Имя пользователя изменено"
+// @Failure 400 {object} model.Response "Ошибка в запросе"
+// @Router /api/set-username [post]
+func (app *WebApp) HandleSetUsername(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("authToken")
+	if err != nil {
+		log.App.Error("Ошибка: отсутствует токен авторизации.") // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Отсутствует токен авторизации"}), http.StatusBadRequest)
+		return
+	}
+	token := cookie.Value
+
+	var req model.SetUsernameRequest
+
+	// Декодируем JSON из тела запроса в структуру
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при распарсивании запроса: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: "Не удалось распарсить запрос: " + err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	response, err := auth.SetUsername(token, req)
+	if err != nil {
+		log.App.Error(fmt.Sprintf("Ошибка при смене имени пользователя: %v", err)) // Логгируем ошибку
+		http.Error(w, utils.StructToJSONString(model.Response{Status: false, Message: err.Error()}), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
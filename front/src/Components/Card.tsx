@@ -109,10 +109,9 @@ const Card: React.FC<CardProps> = ({
           isExpanded ? "max-h-[69.44vw] sm:max-h-[45.14vw]" : "max-h-[8.68vw] sm:max-h-[5.64vw]"
         }`}
       >
-        <div
-          className="font-inter text-[1.73vw] sm:text-[1.12vw] font-normal text-textPrimary mb-[1.39vw] sm:mb-[0.9vw]"
-          dangerouslySetInnerHTML={{ __html: content }} // Используем dangerouslySetInnerHTML
-        />
+        <div className="font-inter text-[1.73vw] sm:text-[1.12vw] font-normal text-textPrimary mb-[1.39vw] sm:mb-[0.9vw]">
+          {content}
+        </div>
       </div>
       <div className="flex justify-start items-left gap-[0.69vw] sm:gap-[0.45vw] font-inter text-[1.73vw] sm:text-[1.12vw] font-normal text-textSecondary mb-[1.82vw] sm:mb-[1.18vw]">
         <img
@@ -90,7 +90,7 @@ const Feed = () => {
       console.log("Совпадение по начальной дате:", matchesStartDate);
       console.log("Совпадение по конечной дате:", matchesEndDate);
 
-      const matchesText = search ? searchRegex.test(post.title) || searchRegex.test(post.content) : true;
+      const matchesText = search ? searchRegex.test(post.title) || searchRegex.test(post.excerpt) : true;
       console.log("Совпадение по тексту:", matchesText);
 
       return matchesAuthor && matchesTags && matchesStartDate && matchesEndDate && matchesText;
@@ -222,11 +222,11 @@ const Feed = () => {
         {filteredPosts.map((post) => (
           <Card
             key={post.id}
-            initialLiked={post.initialLiked}
+            initialLiked={post.myReactions.includes("like")}
             title={post.title}
             subtitle={post.subtitle}
             likes={post.likes}
-            content={post.content}
+            content={post.excerpt}
             tags={post.tags}
             id={post.id}
             authorId={post.authorId}
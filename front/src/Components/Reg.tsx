@@ -15,6 +15,7 @@ const Reg = () => {
   const [code, setCode] = useState("");
   const [errorMessage, setErrorMessage] = useState("");
   const [isCodeSent, setIsCodeSent] = useState(false);
+  const [isSending, setIsSending] = useState(false); // Идет решение задачи проверки и отправка письма
 
   const isValidEmail = /^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(email);
   const isValidPassword = /^(?=.*[A-Z])(?=.*[0-9])(?=.{8,})/.test(password);
@@ -53,7 +54,9 @@ const Reg = () => {
       return;
     }
 
+    setIsSending(true);
     const result = await registerUser(email, password, name);
+    setIsSending(false);
     if (result.status === true) {
       setIsCodeSent(true);
     } else {
@@ -153,8 +156,9 @@ const Reg = () => {
         text-[1.11vw] font-clashDisplay font-normal
         flex gap-[1.11vw] items-center justify-center bg-bgRegCardBtn"
                 onClick={handleRegister}
+                disabled={isSending}
               >
-                ОТПРАВИТЬ
+                {isSending ? "ОТПРАВКА..." : "ОТПРАВИТЬ"}
                 <img
                   src={buttonArrow}
                   alt="buttonArrow"
@@ -24,6 +24,7 @@ import {
   SetPasswordResponse,
 } from "./types";
 import axios from "axios";
+import { proofOfWork } from "./pow";
 
 // Обработка ответа
 const handleResponse = (
@@ -123,10 +124,12 @@ export const registerUser = async (
   name: string
 ): Promise<RegisterResponse> => {
   try {
+    const proof = await proofOfWork("register", email);
     const response = await axios.post<RegisterResponse>("/api/reg", {
       email,
       password,
       name,
+      ...proof,
     } as RegisterRequest);
     return handleResponse(response);
   } catch (error: any) {
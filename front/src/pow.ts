@@ -0,0 +1,54 @@
+import axios from "axios";
+import { PowChallengeResponse, PowScope, ProofOfWork } from "./types";
+
+// Количество нулевых бит в начале хеша
+const leadingZeroBits = (hash: Uint8Array): number => {
+  let count = 0;
+  for (const byte of hash) {
+    if (byte !== 0) {
+      return count + Math.clz32(byte) - 24;
+    }
+    count += 8;
+  }
+  return count;
+};
+
+// Перебор решений: SHA-256 от "challenge:email:solution" должен начинаться с difficulty нулевых бит
+export const solveChallenge = async (
+  challenge: string,
+  email: string,
+  difficulty: number
+): Promise<string> => {
+  const encoder = new TextEncoder();
+  const prefix = `${challenge}:${email.trim().toLowerCase()}:`;
+  for (let n = 0; ; n++) {
+    const solution = String(n);
+    const hash = await crypto.subtle.digest(
+      "SHA-256",
+      encoder.encode(prefix + solution)
+    );
+    if (leadingZeroBits(new Uint8Array(hash)) >= difficulty) {
+      return solution;
+    }
+  }
+};
+
+// Получение и решение задачи перед запросом, отправляющим письмо.
+// Если задачи отключены на сервере, возвращаются пустые поля.
+export const proofOfWork = async (
+  scope: PowScope,
+  email: string
+): Promise<ProofOfWork> => {
+  const response = await axios.post<PowChallengeResponse>(
+    "/api/pow-challenge",
+    { scope }
+  );
+  const { enabled, challenge, difficulty } = response.data;
+  if (!enabled) {
+    return { powChallenge: "", powSolution: "" };
+  }
+  return {
+    powChallenge: challenge,
+    powSolution: await solveChallenge(challenge, email, difficulty),
+  };
+};
@@ -3,7 +3,24 @@ export interface Response {
   message?: string;
 }
 
-export interface RegisterRequest {
+// Решение задачи проверки, которое передается вместе с запросом, отправляющим письмо
+export interface ProofOfWork {
+  powChallenge: string;
+  powSolution: string;
+}
+
+export type PowScope = "register" | "reset-password";
+
+export interface PowChallengeResponse {
+  status: boolean;
+  message?: string;
+  enabled: boolean; // false - задача не требуется
+  challenge: string;
+  difficulty: number; // Количество нулевых бит в начале хеша
+  expiresAt: string;
+}
+
+export interface RegisterRequest extends ProofOfWork {
   email: string;
   password: string;
   name: string;
@@ -110,18 +127,45 @@ export interface PostForFeed {
   title: string;
   subtitle: string;
   likes: number;
-  content: string;
+  excerpt: string; // Отрывок статьи без разметки
+  wordCount: number; // Количество слов
+  readingTime: number; // Время чтения в минутах
   tags: string[];
   date: string;
   authorName: string;
   id: number; // ID поста
   authorId: number; // ID автора
-  initialLiked: boolean; // Новый пропс для начального состояния лайка
+  reactions: Record<string, number>; // Количество реакций по видам
+  myReactions: string[]; // Реакции текущего пользователя
+  bookmarked: boolean; // Пост в закладках текущего пользователя
+  status: string; // published, pending (ждет проверки модератором) или hidden (скрыт модератором)
+  cover: ImageJson | null; // Первое изображение поста
+}
+
+// Вариант изображения
+export interface MediaVariantJson {
+  name: string; // thumbnail, medium или large
+  format: string; // jpeg, png или webp
+  url: string;
+  width: number;
+  height: number;
+  size: number;
+}
+
+// Обработанное изображение с уменьшенными вариантами
+export interface ImageJson {
+  url: string;
+  width: number;
+  height: number;
+  blurhash: string; // Заглушка, пока изображение загружается
+  variants: MediaVariantJson[];
 }
 
 // Запрос на получение всех постов
 export interface GetAllPostsRequest {
-  id: number; // ID автора
+  id: number; // ID автора для /api/get-all-my-posts, 0 - пользователь из токена. Лента всех постов его не учитывает
+  page?: number; // Номер страницы ленты всех постов, начиная с 1
+  limit?: number; // Количество постов на странице ленты всех постов
 }
 
 // Ответ на запрос получения всех постов
@@ -133,12 +177,19 @@ export interface GetAllPostsResponse {
 
 export interface ProfileRequest {
   id: number;
+  username?: string; // Используется, если id не указан
 }
 
 export interface ProfileResponse {
   status: boolean;
   message?: string;
   name: string;
+  username: string; // Имя для упоминаний (@username)
+  followers: number; // Количество подписчиков
+  following: number; // Количество авторов, на которых подписан пользователь
+  followed: boolean; // Текущий пользователь подписан на автора
+  blocked: boolean; // Текущий пользователь заблокировал автора
+  muted: boolean; // Текущий пользователь скрыл автора
 }
 
 export interface SetPasswordRequest {
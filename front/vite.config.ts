@@ -10,6 +10,10 @@ export default defineConfig({
         target: 'http://localhost:8080',
         changeOrigin: true,
         ws: true,
+      },
+      '/media': {
+        target: 'http://localhost:8080',
+        changeOrigin: true,
       }
     }
   }